@@ -0,0 +1,61 @@
+package crypto_test
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"fmt"
+	"testing"
+
+	"github.com/japananh/crypto"
+)
+
+// BenchmarkAEAD compares OCB3 and EAX with the standard library GCM on the same key, nonce and inputs.
+func BenchmarkAEAD(b *testing.B) {
+	key := randomBytes(16)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	modes := []struct {
+		name string
+		new  func(cipher.Block) (cipher.AEAD, error)
+	}{
+		{name: "GCM", new: cipher.NewGCM},
+		{name: "OCB3", new: crypto.NewOCB},
+		{name: "EAX", new: crypto.NewEAX},
+	}
+
+	for _, size := range []int{64, 1024, 8192} {
+		plaintext := randomBytes(size)
+		additionalData := randomBytes(13)
+
+		for _, mode := range modes {
+			aead, err := mode.new(block)
+			if err != nil {
+				b.Fatal(err)
+			}
+			nonce := make([]byte, aead.NonceSize())
+			out := make([]byte, 0, size+aead.Overhead())
+
+			b.Run(fmt.Sprintf("%s/Seal/%d", mode.name, size), func(b *testing.B) {
+				b.SetBytes(int64(size))
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					aead.Seal(out[:0], nonce, plaintext, additionalData)
+				}
+			})
+
+			ciphertext := aead.Seal(nil, nonce, plaintext, additionalData)
+			b.Run(fmt.Sprintf("%s/Open/%d", mode.name, size), func(b *testing.B) {
+				b.SetBytes(int64(size))
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := aead.Open(out[:0], nonce, ciphertext, additionalData); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
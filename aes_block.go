@@ -0,0 +1,268 @@
+package crypto
+
+import (
+	"crypto/cipher"
+	"fmt"
+)
+
+// AESBlockSize is the AES block size in bytes.
+const AESBlockSize = 16
+
+// AESStep names a transformation of the AES state, as used in FIPS-197.
+type AESStep string
+
+const (
+	AESStepSubBytes      AESStep = "SubBytes"
+	AESStepShiftRows     AESStep = "ShiftRows"
+	AESStepMixColumns    AESStep = "MixColumns"
+	AESStepAddRoundKey   AESStep = "AddRoundKey"
+	AESStepInvSubBytes   AESStep = "InvSubBytes"
+	AESStepInvShiftRows  AESStep = "InvShiftRows"
+	AESStepInvMixColumns AESStep = "InvMixColumns"
+)
+
+// AESTracer is called with a copy of the AES state after every step of every round.
+// Round 0 is the initial AddRoundKey; the state after the AddRoundKey step of round r is the output of round r.
+type AESTracer func(round int, step AESStep, state [AESBlockSize]byte)
+
+// aesCipher is a straightforward, byte-oriented AES implementation written for readability.
+// It is NOT constant-time: the table lookups in SubBytes leak timing information,
+// so use crypto/aes for anything other than teaching and debugging.
+type aesCipher struct {
+	roundKeys [][AESBlockSize]byte
+	tracer    AESTracer
+}
+
+// NewAESCipher creates an AES cipher.Block from a 16, 24 or 32-byte key, selecting AES-128, AES-192 or AES-256.
+// It is a drop-in replacement for aes.NewCipher that shows every step of the algorithm in plain Go.
+func NewAESCipher(key []byte) (cipher.Block, error) {
+	return NewAESCipherWithTracer(key, nil)
+}
+
+// NewAESCipherWithTracer is like NewAESCipher, but calls tracer with the state after every step of
+// every round of Encrypt and Decrypt. A nil tracer disables tracing.
+func NewAESCipherWithTracer(key []byte, tracer AESTracer) (cipher.Block, error) {
+	roundKeys, err := ExpandAESKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &aesCipher{roundKeys: roundKeys, tracer: tracer}, nil
+}
+
+// ExpandAESKey runs the AES key schedule and returns the round keys: 11, 13 or 15 keys
+// for AES-128, AES-192 and AES-256 respectively (FIPS-197, section 5.2).
+func ExpandAESKey(key []byte) ([][AESBlockSize]byte, error) {
+	nk := len(key) / 4
+	if len(key) != 16 && len(key) != 24 && len(key) != 32 {
+		return nil, fmt.Errorf("AES key size must be 16, 24 or 32 bytes, got %d", len(key))
+	}
+	nr := nk + 6
+
+	// w holds the expanded key as 4-byte words
+	w := make([][4]byte, 4*(nr+1))
+	for i := 0; i < nk; i++ {
+		copy(w[i][:], key[4*i:])
+	}
+
+	rcon := byte(0x01)
+	for i := nk; i < len(w); i++ {
+		temp := w[i-1]
+		if i%nk == 0 {
+			// RotWord, SubWord and the round constant
+			temp = [4]byte{sbox[temp[1]] ^ rcon, sbox[temp[2]], sbox[temp[3]], sbox[temp[0]]}
+			rcon = gfMul(rcon, 2)
+		} else if nk > 6 && i%nk == 4 {
+			temp = [4]byte{sbox[temp[0]], sbox[temp[1]], sbox[temp[2]], sbox[temp[3]]}
+		}
+		for j := range temp {
+			w[i][j] = w[i-nk][j] ^ temp[j]
+		}
+	}
+
+	roundKeys := make([][AESBlockSize]byte, nr+1)
+	for r := range roundKeys {
+		for c := 0; c < 4; c++ {
+			copy(roundKeys[r][4*c:], w[4*r+c][:])
+		}
+	}
+
+	return roundKeys, nil
+}
+
+func (c *aesCipher) BlockSize() int {
+	return AESBlockSize
+}
+
+// Encrypt encrypts one block (FIPS-197, section 5.1).
+func (c *aesCipher) Encrypt(dst, src []byte) {
+	if len(src) < AESBlockSize || len(dst) < AESBlockSize {
+		panic("crypto/aes: input not full block")
+	}
+
+	var state [AESBlockSize]byte
+	copy(state[:], src)
+
+	nr := len(c.roundKeys) - 1
+
+	addRoundKey(&state, &c.roundKeys[0])
+	c.trace(0, AESStepAddRoundKey, &state)
+
+	for round := 1; round <= nr; round++ {
+		subBytes(&state, &sbox)
+		c.trace(round, AESStepSubBytes, &state)
+
+		shiftRows(&state)
+		c.trace(round, AESStepShiftRows, &state)
+
+		// The last round has no MixColumns
+		if round != nr {
+			mixColumns(&state)
+			c.trace(round, AESStepMixColumns, &state)
+		}
+
+		addRoundKey(&state, &c.roundKeys[round])
+		c.trace(round, AESStepAddRoundKey, &state)
+	}
+
+	copy(dst, state[:])
+}
+
+// Decrypt decrypts one block with the straightforward inverse cipher (FIPS-197, section 5.3).
+// Rounds are numbered in processing order, so round 0 undoes the last encryption round key.
+func (c *aesCipher) Decrypt(dst, src []byte) {
+	if len(src) < AESBlockSize || len(dst) < AESBlockSize {
+		panic("crypto/aes: input not full block")
+	}
+
+	var state [AESBlockSize]byte
+	copy(state[:], src)
+
+	nr := len(c.roundKeys) - 1
+
+	addRoundKey(&state, &c.roundKeys[nr])
+	c.trace(0, AESStepAddRoundKey, &state)
+
+	for round := 1; round <= nr; round++ {
+		invShiftRows(&state)
+		c.trace(round, AESStepInvShiftRows, &state)
+
+		subBytes(&state, &invSbox)
+		c.trace(round, AESStepInvSubBytes, &state)
+
+		addRoundKey(&state, &c.roundKeys[nr-round])
+		c.trace(round, AESStepAddRoundKey, &state)
+
+		if round != nr {
+			invMixColumns(&state)
+			c.trace(round, AESStepInvMixColumns, &state)
+		}
+	}
+
+	copy(dst, state[:])
+}
+
+func (c *aesCipher) trace(round int, step AESStep, state *[AESBlockSize]byte) {
+	if c.tracer != nil {
+		c.tracer(round, step, *state)
+	}
+}
+
+// The state is stored column by column, as in the input block: byte (row r, column c) is state[4*c+r].
+
+func addRoundKey(state, roundKey *[AESBlockSize]byte) {
+	for i := range state {
+		state[i] ^= roundKey[i]
+	}
+}
+
+func subBytes(state *[AESBlockSize]byte, box *[256]byte) {
+	for i := range state {
+		state[i] = box[state[i]]
+	}
+}
+
+// shiftRows rotates row r of the state left by r positions.
+func shiftRows(state *[AESBlockSize]byte) {
+	old := *state
+	for c := 0; c < 4; c++ {
+		for r := 0; r < 4; r++ {
+			state[4*c+r] = old[4*((c+r)%4)+r]
+		}
+	}
+}
+
+// invShiftRows rotates row r of the state right by r positions.
+func invShiftRows(state *[AESBlockSize]byte) {
+	old := *state
+	for c := 0; c < 4; c++ {
+		for r := 0; r < 4; r++ {
+			state[4*((c+r)%4)+r] = old[4*c+r]
+		}
+	}
+}
+
+// mixColumns multiplies every column by the fixed polynomial {03}x^3 + {01}x^2 + {01}x + {02}.
+func mixColumns(state *[AESBlockSize]byte) {
+	for c := 0; c < 4; c++ {
+		a0, a1, a2, a3 := state[4*c], state[4*c+1], state[4*c+2], state[4*c+3]
+		state[4*c] = gfMul(a0, 2) ^ gfMul(a1, 3) ^ a2 ^ a3
+		state[4*c+1] = a0 ^ gfMul(a1, 2) ^ gfMul(a2, 3) ^ a3
+		state[4*c+2] = a0 ^ a1 ^ gfMul(a2, 2) ^ gfMul(a3, 3)
+		state[4*c+3] = gfMul(a0, 3) ^ a1 ^ a2 ^ gfMul(a3, 2)
+	}
+}
+
+// invMixColumns multiplies every column by {0b}x^3 + {0d}x^2 + {09}x + {0e}, the inverse of mixColumns.
+func invMixColumns(state *[AESBlockSize]byte) {
+	for c := 0; c < 4; c++ {
+		a0, a1, a2, a3 := state[4*c], state[4*c+1], state[4*c+2], state[4*c+3]
+		state[4*c] = gfMul(a0, 0x0e) ^ gfMul(a1, 0x0b) ^ gfMul(a2, 0x0d) ^ gfMul(a3, 0x09)
+		state[4*c+1] = gfMul(a0, 0x09) ^ gfMul(a1, 0x0e) ^ gfMul(a2, 0x0b) ^ gfMul(a3, 0x0d)
+		state[4*c+2] = gfMul(a0, 0x0d) ^ gfMul(a1, 0x09) ^ gfMul(a2, 0x0e) ^ gfMul(a3, 0x0b)
+		state[4*c+3] = gfMul(a0, 0x0b) ^ gfMul(a1, 0x0d) ^ gfMul(a2, 0x09) ^ gfMul(a3, 0x0e)
+	}
+}
+
+// gfMul multiplies two elements of GF(2^8) modulo the AES polynomial x^8 + x^4 + x^3 + x + 1.
+func gfMul(a, b byte) byte {
+	var p byte
+	for b > 0 {
+		if b&1 != 0 {
+			p ^= a
+		}
+		// xtime: multiply a by x
+		carry := a & 0x80
+		a <<= 1
+		if carry != 0 {
+			a ^= 0x1b
+		}
+		b >>= 1
+	}
+	return p
+}
+
+// sbox and invSbox are derived at start-up rather than hard-coded, to show where the S-box comes from:
+// the multiplicative inverse in GF(2^8) followed by an affine transformation (FIPS-197, section 5.1.1).
+var sbox, invSbox = func() (box, inv [256]byte) {
+	for x := 0; x < 256; x++ {
+		// The inverse of 0 is defined as 0; a^254 = a^-1 for every other element
+		var b byte
+		if x != 0 {
+			b = 1
+			for i := 0; i < 254; i++ {
+				b = gfMul(b, byte(x))
+			}
+		}
+
+		// Affine transformation: b ^ rotl(b,1) ^ rotl(b,2) ^ rotl(b,3) ^ rotl(b,4) ^ 0x63
+		s := b ^ rotl8(b, 1) ^ rotl8(b, 2) ^ rotl8(b, 3) ^ rotl8(b, 4) ^ 0x63
+		box[x] = s
+		inv[s] = byte(x)
+	}
+	return box, inv
+}()
+
+func rotl8(b byte, n uint) byte {
+	return b<<n | b>>(8-n)
+}
@@ -0,0 +1,253 @@
+package crypto_test
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"fmt"
+	"hash"
+
+	"github.com/japananh/crypto"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("crypto - aes block", func() {
+	decodeHex := func(s string) []byte {
+		b, err := hex.DecodeString(s)
+		Expect(err).NotTo(HaveOccurred())
+		return b
+	}
+
+	Describe("Encrypt - Decrypt", func() {
+		// Example vectors from FIPS-197, Appendix C
+		fipsTestVectors := []struct {
+			name       string
+			key        string
+			ciphertext string
+		}{
+			{name: "C.1 AES-128", key: "000102030405060708090a0b0c0d0e0f", ciphertext: "69c4e0d86a7b0430d8cdb78070b4c55a"},
+			{name: "C.2 AES-192", key: "000102030405060708090a0b0c0d0e0f1011121314151617", ciphertext: "dda97ca4864cdfe06eaf70a0ec0d7191"},
+			{name: "C.3 AES-256", key: "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f", ciphertext: "8ea2b7ca516745bfeafc49904b496089"},
+		}
+
+		for _, tt := range fipsTestVectors {
+			tt := tt
+			It("should match FIPS-197 "+tt.name, func() {
+				block, err := crypto.NewAESCipher(decodeHex(tt.key))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(block.BlockSize()).To(Equal(aes.BlockSize))
+
+				plaintext := decodeHex("00112233445566778899aabbccddeeff")
+				ciphertext := make([]byte, aes.BlockSize)
+				block.Encrypt(ciphertext, plaintext)
+				Expect(hex.EncodeToString(ciphertext)).To(Equal(tt.ciphertext))
+
+				decrypted := make([]byte, aes.BlockSize)
+				block.Decrypt(decrypted, ciphertext)
+				Expect(decrypted).To(Equal(plaintext))
+			})
+		}
+
+		for _, keySize := range []int{16, 24, 32} {
+			keySize := keySize
+			It(fmt.Sprintf("should match crypto/aes on random %d-byte keys and blocks", keySize), func() {
+				for i := 0; i < 200; i++ {
+					key := randomBytes(keySize)
+					src := randomBytes(aes.BlockSize)
+
+					ours, err := crypto.NewAESCipher(key)
+					Expect(err).NotTo(HaveOccurred())
+					stdlib, err := aes.NewCipher(key)
+					Expect(err).NotTo(HaveOccurred())
+
+					got, want := make([]byte, aes.BlockSize), make([]byte, aes.BlockSize)
+					ours.Encrypt(got, src)
+					stdlib.Encrypt(want, src)
+					Expect(got).To(Equal(want))
+
+					ours.Decrypt(got, src)
+					stdlib.Decrypt(want, src)
+					Expect(got).To(Equal(want))
+				}
+			})
+		}
+
+		It("should reject an invalid key size", func() {
+			for _, size := range []int{0, 8, 15, 17, 33} {
+				_, err := crypto.NewAESCipher(make([]byte, size))
+				Expect(err).To(HaveOccurred())
+			}
+		})
+	})
+
+	Describe("ExpandAESKey", func() {
+		It("should match the FIPS-197 Appendix A.1 key expansion", func() {
+			roundKeys, err := crypto.ExpandAESKey(decodeHex("2b7e151628aed2a6abf7158809cf4f3c"))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(roundKeys).To(HaveLen(11))
+			Expect(hex.EncodeToString(roundKeys[0][:])).To(Equal("2b7e151628aed2a6abf7158809cf4f3c"))
+			Expect(hex.EncodeToString(roundKeys[1][:])).To(Equal("a0fafe1788542cb123a339392a6c7605"))
+			Expect(hex.EncodeToString(roundKeys[10][:])).To(Equal("d014f9a8c9ee2589e13f0cc8b6630ca6"))
+		})
+
+		It("should produce 13 and 15 round keys for AES-192 and AES-256", func() {
+			roundKeys, err := crypto.ExpandAESKey(make([]byte, 24))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(roundKeys).To(HaveLen(13))
+
+			roundKeys, err = crypto.ExpandAESKey(make([]byte, 32))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(roundKeys).To(HaveLen(15))
+		})
+	})
+
+	Describe("NewAESCipherWithTracer", func() {
+		type traceEntry struct {
+			round int
+			step  crypto.AESStep
+			state string
+		}
+
+		It("should record the FIPS-197 Appendix C.1 cipher states", func() {
+			var trace []traceEntry
+			block, err := crypto.NewAESCipherWithTracer(decodeHex("000102030405060708090a0b0c0d0e0f"), func(round int, step crypto.AESStep, state [crypto.AESBlockSize]byte) {
+				trace = append(trace, traceEntry{round: round, step: step, state: hex.EncodeToString(state[:])})
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			ciphertext := make([]byte, aes.BlockSize)
+			block.Encrypt(ciphertext, decodeHex("00112233445566778899aabbccddeeff"))
+
+			// 1 initial AddRoundKey, 9 full rounds of 4 steps and a final round without MixColumns
+			Expect(trace).To(HaveLen(1 + 9*4 + 3))
+			Expect(trace[:9]).To(Equal([]traceEntry{
+				{round: 0, step: crypto.AESStepAddRoundKey, state: "00102030405060708090a0b0c0d0e0f0"},
+				{round: 1, step: crypto.AESStepSubBytes, state: "63cab7040953d051cd60e0e7ba70e18c"},
+				{round: 1, step: crypto.AESStepShiftRows, state: "6353e08c0960e104cd70b751bacad0e7"},
+				{round: 1, step: crypto.AESStepMixColumns, state: "5f72641557f5bc92f7be3b291db9f91a"},
+				{round: 1, step: crypto.AESStepAddRoundKey, state: "89d810e8855ace682d1843d8cb128fe4"},
+				{round: 2, step: crypto.AESStepSubBytes, state: "a761ca9b97be8b45d8ad1a611fc97369"},
+				{round: 2, step: crypto.AESStepShiftRows, state: "a7be1a6997ad739bd8c9ca451f618b61"},
+				{round: 2, step: crypto.AESStepMixColumns, state: "ff87968431d86a51645151fa773ad009"},
+				{round: 2, step: crypto.AESStepAddRoundKey, state: "4915598f55e5d7a0daca94fa1f0a63f7"},
+			}))
+			Expect(trace[len(trace)-1]).To(Equal(traceEntry{round: 10, step: crypto.AESStepAddRoundKey, state: "69c4e0d86a7b0430d8cdb78070b4c55a"}))
+		})
+
+		It("should record the FIPS-197 Appendix C.1 inverse cipher states", func() {
+			var trace []traceEntry
+			block, err := crypto.NewAESCipherWithTracer(decodeHex("000102030405060708090a0b0c0d0e0f"), func(round int, step crypto.AESStep, state [crypto.AESBlockSize]byte) {
+				trace = append(trace, traceEntry{round: round, step: step, state: hex.EncodeToString(state[:])})
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			plaintext := make([]byte, aes.BlockSize)
+			block.Decrypt(plaintext, decodeHex("69c4e0d86a7b0430d8cdb78070b4c55a"))
+
+			Expect(trace[:5]).To(Equal([]traceEntry{
+				{round: 0, step: crypto.AESStepAddRoundKey, state: "7ad5fda789ef4e272bca100b3d9ff59f"},
+				{round: 1, step: crypto.AESStepInvShiftRows, state: "7a9f102789d5f50b2beffd9f3dca4ea7"},
+				{round: 1, step: crypto.AESStepInvSubBytes, state: "bd6e7c3df2b5779e0b61216e8b10b689"},
+				{round: 1, step: crypto.AESStepAddRoundKey, state: "e9f74eec023020f61bf2ccf2353c21c7"},
+				{round: 1, step: crypto.AESStepInvMixColumns, state: "54d990a16ba09ab596bbf40ea111702f"},
+			}))
+			Expect(trace[len(trace)-1]).To(Equal(traceEntry{round: 10, step: crypto.AESStepAddRoundKey, state: "00112233445566778899aabbccddeeff"}))
+		})
+	})
+
+	Describe("modes of operation", func() {
+		// Every mode must produce exactly the same output whether it runs on crypto/aes or on our implementation
+		key := randomBytes(32)
+		iv := randomBytes(aes.BlockSize)
+		plaintext := randomBytes(5 * aes.BlockSize)
+
+		newBlocks := func() (ours, stdlib cipher.Block) {
+			ours, err := crypto.NewAESCipher(key)
+			Expect(err).NotTo(HaveOccurred())
+			stdlib, err = aes.NewCipher(key)
+			Expect(err).NotTo(HaveOccurred())
+			return ours, stdlib
+		}
+
+		It("should be accepted by CBC", func() {
+			ours, stdlib := newBlocks()
+
+			got, want := make([]byte, len(plaintext)), make([]byte, len(plaintext))
+			cipher.NewCBCEncrypter(ours, iv).CryptBlocks(got, plaintext)
+			cipher.NewCBCEncrypter(stdlib, iv).CryptBlocks(want, plaintext)
+			Expect(got).To(Equal(want))
+
+			cipher.NewCBCDecrypter(ours, iv).CryptBlocks(got, want)
+			Expect(got).To(Equal(plaintext))
+		})
+
+		It("should be accepted by CFB, OFB and CTR", func() {
+			ours, stdlib := newBlocks()
+
+			streams := []func(cipher.Block) cipher.Stream{
+				func(b cipher.Block) cipher.Stream { return cipher.NewCFBEncrypter(b, iv) },
+				func(b cipher.Block) cipher.Stream { return cipher.NewOFB(b, iv) },
+				func(b cipher.Block) cipher.Stream { return cipher.NewCTR(b, iv) },
+			}
+			for _, newStream := range streams {
+				got, want := make([]byte, len(plaintext)-3), make([]byte, len(plaintext)-3)
+				newStream(ours).XORKeyStream(got, plaintext[3:])
+				newStream(stdlib).XORKeyStream(want, plaintext[3:])
+				Expect(got).To(Equal(want))
+			}
+		})
+
+		It("should be accepted by GCM, OCB and EAX", func() {
+			ours, stdlib := newBlocks()
+
+			aeads := []func(cipher.Block) (cipher.AEAD, error){cipher.NewGCM, crypto.NewOCB, crypto.NewEAX}
+			for _, newAEAD := range aeads {
+				oursAEAD, err := newAEAD(ours)
+				Expect(err).NotTo(HaveOccurred())
+				stdlibAEAD, err := newAEAD(stdlib)
+				Expect(err).NotTo(HaveOccurred())
+
+				nonce := iv[:oursAEAD.NonceSize()]
+				got := oursAEAD.Seal(nil, nonce, plaintext, []byte("header"))
+				Expect(got).To(Equal(stdlibAEAD.Seal(nil, nonce, plaintext, []byte("header"))))
+
+				decrypted, err := oursAEAD.Open(nil, nonce, got, []byte("header"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(decrypted).To(Equal(plaintext))
+			}
+		})
+
+		It("should be accepted by CMAC and PMAC", func() {
+			ours, stdlib := newBlocks()
+
+			for _, newMAC := range []func(cipher.Block) (hash.Hash, error){crypto.NewCMAC, crypto.NewPMAC} {
+				oursMAC, err := newMAC(ours)
+				Expect(err).NotTo(HaveOccurred())
+				stdlibMAC, err := newMAC(stdlib)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, _ = oursMAC.Write(plaintext)
+				_, _ = stdlibMAC.Write(plaintext)
+				Expect(oursMAC.Sum(nil)).To(Equal(stdlibMAC.Sum(nil)))
+			}
+		})
+
+		It("should be accepted by XTS", func() {
+			xtsKey := randomBytes(64)
+			ours, err := crypto.NewSectorCipher(crypto.NewAESCipher, xtsKey, crypto.DefaultSectorSize)
+			Expect(err).NotTo(HaveOccurred())
+			stdlib, err := crypto.NewSectorCipher(aes.NewCipher, xtsKey, crypto.DefaultSectorSize)
+			Expect(err).NotTo(HaveOccurred())
+
+			sector := randomBytes(crypto.DefaultSectorSize - 1)
+			got, want := make([]byte, len(sector)), make([]byte, len(sector))
+			Expect(ours.EncryptSector(got, sector, 3)).To(Succeed())
+			Expect(stdlib.EncryptSector(want, sector, 3)).To(Succeed())
+			Expect(bytes.Equal(got, want)).To(BeTrue())
+		})
+	})
+})
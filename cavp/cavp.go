@@ -0,0 +1,184 @@
+// Package cavp parses NIST Cryptographic Algorithm Validation Program (CAVP) response files (.rsp)
+// and runs their known-answer tests against an implementation.
+//
+// A response file is a sequence of sections introduced by bracketed parameters such as [ENCRYPT] or
+// [Keylen = 128], each followed by test cases made of "NAME = value" lines and separated by blank lines.
+package cavp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// TestCase is a single test vector of a response file.
+type TestCase struct {
+	// File and Line locate the first line of the test case, for error reports.
+	File string
+	Line int
+
+	// Params holds the bracketed parameters of the enclosing section, e.g. "ENCRYPT" -> "" or "KEYLEN" -> "128".
+	// Fields holds the values of the test case, e.g. "KEY" -> "00112233...". Names are upper-cased.
+	Params map[string]string
+	Fields map[string]string
+}
+
+// Decrypt reports whether the test case belongs to a [DECRYPT] section.
+func (tc TestCase) Decrypt() bool {
+	_, ok := tc.Params["DECRYPT"]
+	return ok
+}
+
+// Has reports whether the test case has the given field.
+func (tc TestCase) Has(name string) bool {
+	_, ok := tc.Fields[strings.ToUpper(name)]
+	return ok
+}
+
+// Hex decodes the given field from hex. An empty field decodes to an empty slice.
+func (tc TestCase) Hex(name string) ([]byte, error) {
+	value, ok := tc.Fields[strings.ToUpper(name)]
+	if !ok {
+		return nil, fmt.Errorf("%s: missing field %s", tc, strings.ToUpper(name))
+	}
+
+	b, err := hex.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: decode field %s: %w", tc, strings.ToUpper(name), err)
+	}
+
+	return b, nil
+}
+
+// Check compares got with the hex-encoded value of the given field.
+func (tc TestCase) Check(name string, got []byte) error {
+	want, err := tc.Hex(name)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(got, want) {
+		return fmt.Errorf("%s mismatch: got %x, want %x", strings.ToUpper(name), got, want)
+	}
+	return nil
+}
+
+// String identifies the test case by file, line, section and count, e.g. "CBCGFSbox128.rsp:9 [ENCRYPT] COUNT = 0".
+func (tc TestCase) String() string {
+	section := ""
+	if tc.Decrypt() {
+		section = " [DECRYPT]"
+	} else if _, ok := tc.Params["ENCRYPT"]; ok {
+		section = " [ENCRYPT]"
+	}
+
+	return fmt.Sprintf("%s:%d%s COUNT = %s", filepath.Base(tc.File), tc.Line, section, tc.Fields["COUNT"])
+}
+
+// Parse reads the test cases of a response file. name is only used to label the test cases.
+func Parse(r io.Reader, name string) ([]TestCase, error) {
+	var (
+		cases   []TestCase
+		params  = map[string]string{}
+		current *TestCase
+		// A new bracketed line after test cases starts a new section rather than extending the current one
+		inParams bool
+	)
+
+	flush := func() {
+		if current != nil {
+			cases = append(cases, *current)
+			current = nil
+		}
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "":
+			flush()
+
+		case strings.HasPrefix(line, "#"):
+			// Comment
+
+		case strings.HasPrefix(line, "["):
+			flush()
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("%s:%d: malformed section header %q", name, lineNum, line)
+			}
+			if !inParams {
+				params = map[string]string{}
+				inParams = true
+			}
+
+			key, value, _ := strings.Cut(line[1:len(line)-1], "=")
+			params[strings.ToUpper(strings.TrimSpace(key))] = strings.TrimSpace(value)
+
+		default:
+			inParams = false
+			if current == nil {
+				current = &TestCase{File: name, Line: lineNum, Params: params, Fields: map[string]string{}}
+			}
+
+			// Lines without "=", such as FAIL in GCM decryption files, are flags with an empty value
+			key, value, _ := strings.Cut(line, "=")
+			current.Fields[strings.ToUpper(strings.TrimSpace(key))] = strings.TrimSpace(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", name, err)
+	}
+	flush()
+
+	return cases, nil
+}
+
+// ParseFile reads the test cases of the response file at path.
+func ParseFile(path string) ([]TestCase, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return Parse(file, path)
+}
+
+// Failure is a test case that an implementation got wrong.
+type Failure struct {
+	Case TestCase
+	Err  error
+}
+
+func (f Failure) Error() string {
+	return fmt.Sprintf("%s: %v", f.Case, f.Err)
+}
+
+// Run parses the response file at path and calls check for every test case.
+// It returns one Failure for every test case check returns an error for.
+func Run(path string, check func(TestCase) error) ([]Failure, error) {
+	cases, err := ParseFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(cases) == 0 {
+		return nil, fmt.Errorf("%s: no test cases", path)
+	}
+
+	var failures []Failure
+	for _, tc := range cases {
+		if err := check(tc); err != nil {
+			failures = append(failures, Failure{Case: tc, Err: err})
+		}
+	}
+
+	return failures, nil
+}
@@ -0,0 +1,13 @@
+package cavp_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestCAVP(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "CAVP Suit")
+}
@@ -0,0 +1,98 @@
+package cavp_test
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+
+	"github.com/japananh/crypto/cavp"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("cavp", func() {
+	const sample = `# CAVS 11.1
+# Sample response file
+
+[ENCRYPT]
+
+COUNT = 0
+KEY = 000102
+PLAINTEXT = aabb
+
+COUNT = 1
+KEY = 030405
+PLAINTEXT =
+
+[DECRYPT]
+
+COUNT = 0
+KEY = 060708
+CIPHERTEXT = ccdd
+
+[Keylen = 128]
+[IVlen = 96]
+
+Count = 0
+Key = 090a0b
+FAIL
+`
+
+	It("should parse sections, fields and flags", func() {
+		cases, err := cavp.Parse(strings.NewReader(sample), "sample.rsp")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cases).To(HaveLen(4))
+
+		Expect(cases[0].Line).To(Equal(6))
+		Expect(cases[0].Decrypt()).To(BeFalse())
+		Expect(cases[0].Fields).To(Equal(map[string]string{"COUNT": "0", "KEY": "000102", "PLAINTEXT": "aabb"}))
+		Expect(cases[0].String()).To(Equal("sample.rsp:6 [ENCRYPT] COUNT = 0"))
+
+		plaintext, err := cases[1].Hex("plaintext")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(plaintext).To(BeEmpty())
+
+		Expect(cases[2].Decrypt()).To(BeTrue())
+		Expect(cases[2].Check("CIPHERTEXT", []byte{0xcc, 0xdd})).To(Succeed())
+		Expect(cases[2].Check("CIPHERTEXT", []byte{0xcc})).To(MatchError(ContainSubstring("CIPHERTEXT mismatch")))
+
+		Expect(cases[3].Params).To(Equal(map[string]string{"KEYLEN": "128", "IVLEN": "96"}))
+		Expect(cases[3].Has("FAIL")).To(BeTrue())
+		Expect(cases[3].Decrypt()).To(BeFalse())
+	})
+
+	It("should reject missing and malformed fields", func() {
+		cases, err := cavp.Parse(strings.NewReader("COUNT = 0\nKEY = 0g\n"), "bad.rsp")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = cases[0].Hex("KEY")
+		Expect(err).To(HaveOccurred())
+		_, err = cases[0].Hex("IV")
+		Expect(err).To(MatchError(ContainSubstring("missing field IV")))
+
+		_, err = cavp.Parse(strings.NewReader("[ENCRYPT\n"), "bad.rsp")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should report every failing test case", func() {
+		path := filepath.Join("testdata", "ECBGFSbox128.rsp")
+
+		failures, err := cavp.Run(path, func(tc cavp.TestCase) error {
+			if tc.Decrypt() {
+				return errors.New("decryption not supported")
+			}
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(failures).To(HaveLen(7))
+		for _, failure := range failures {
+			Expect(failure.Case.Decrypt()).To(BeTrue())
+			Expect(failure.Error()).To(HavePrefix("ECBGFSbox128.rsp:"))
+			Expect(failure.Error()).To(HaveSuffix("decryption not supported"))
+		}
+
+		_, err = cavp.Run(filepath.Join("testdata", "missing.rsp"), func(cavp.TestCase) error { return nil })
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,132 @@
+package crypto_test
+
+import (
+	"bytes"
+	"path/filepath"
+
+	"github.com/japananh/crypto"
+	"github.com/japananh/crypto/cavp"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("crypto - cavp", func() {
+	testdata := func(name string) string {
+		return filepath.Join("cavp", "testdata", name)
+	}
+
+	// checkECB runs an AESAVS ECB test case through NewAESCipher, one block at a time.
+	checkECB := func(tc cavp.TestCase) error {
+		key, err := tc.Hex("KEY")
+		if err != nil {
+			return err
+		}
+		block, err := crypto.NewAESCipher(key)
+		if err != nil {
+			return err
+		}
+
+		in, want := "PLAINTEXT", "CIPHERTEXT"
+		crypt := block.Encrypt
+		if tc.Decrypt() {
+			in, want = want, in
+			crypt = block.Decrypt
+		}
+
+		src, err := tc.Hex(in)
+		if err != nil {
+			return err
+		}
+		dst := make([]byte, len(src))
+		for i := 0; i < len(src); i += crypto.AESBlockSize {
+			crypt(dst[i:], src[i:])
+		}
+
+		return tc.Check(want, dst)
+	}
+
+	It("should pass the AESAVS ECB known-answer tests", func() {
+		for _, file := range []string{"ECBGFSbox128.rsp", "ECBKeySbox128.rsp", "ECBVarTxt128.rsp", "ECBSP800-38A.rsp"} {
+			failures, err := cavp.Run(testdata(file), checkECB)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(failures).To(BeEmpty())
+		}
+	})
+
+	It("should pass the GCMVS known-answer tests", func() {
+		failures, err := cavp.Run(testdata("gcmEncryptExtIV128.rsp"), func(tc cavp.TestCase) error {
+			values := map[string][]byte{}
+			for _, name := range []string{"KEY", "IV", "PT", "AAD", "CT", "TAG"} {
+				value, err := tc.Hex(name)
+				if err != nil {
+					return err
+				}
+				values[name] = value
+			}
+
+			block, err := crypto.NewAESCipher(values["KEY"])
+			if err != nil {
+				return err
+			}
+			aead, err := crypto.NewGCMWithNonceSize(block, len(values["IV"]))
+			if err != nil {
+				return err
+			}
+
+			sealed := aead.Seal(nil, values["IV"], values["PT"], values["AAD"])
+			if err := tc.Check("CT", sealed[:len(values["PT"])]); err != nil {
+				return err
+			}
+			if err := tc.Check("TAG", sealed[len(values["PT"]):]); err != nil {
+				return err
+			}
+
+			opened, err := aead.Open(nil, values["IV"], sealed, values["AAD"])
+			if err != nil {
+				return err
+			}
+			return tc.Check("PT", opened)
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(failures).To(BeEmpty())
+	})
+
+	It("should decrypt the GCMVS vectors that fit in a single AESGCMDecrypt chunk", func() {
+		// AESGCMDecrypt reads chunks of nonce || ciphertext || tag with a 96-bit nonce, a 128-bit tag and no
+		// additional data, so only the vectors with that shape and at most AESGCMChunkSize bytes of ciphertext apply
+		checked := 0
+		failures, err := cavp.Run(testdata("gcmEncryptExtIV128.rsp"), func(tc cavp.TestCase) error {
+			if tc.Params["IVLEN"] != "96" || tc.Params["TAGLEN"] != "128" || tc.Fields["AAD"] != "" {
+				return nil
+			}
+			ciphertext, err := tc.Hex("CT")
+			if err != nil || len(ciphertext) > crypto.AESGCMChunkSize {
+				return err
+			}
+
+			var chunk []byte
+			for _, name := range []string{"IV", "CT", "TAG"} {
+				value, err := tc.Hex(name)
+				if err != nil {
+					return err
+				}
+				chunk = append(chunk, value...)
+			}
+			key, err := tc.Hex("KEY")
+			if err != nil {
+				return err
+			}
+
+			checked++
+			plaintext, err := crypto.AESGCMDecrypt(bytes.NewReader(chunk), key)
+			if err != nil {
+				return err
+			}
+			return tc.Check("PT", plaintext)
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(failures).To(BeEmpty())
+		Expect(checked).To(BeNumerically(">", 0))
+	})
+})
@@ -1,3 +1,13 @@
 module cbc
 
 go 1.20
+
+require (
+	cryptography-challenge v0.0.0
+	github.com/japananh/crypto v0.0.0
+)
+
+replace (
+	cryptography-challenge => ../cryptopals
+	github.com/japananh/crypto => ../
+)
@@ -1,112 +1,45 @@
 package main
 
 import (
-	"bytes"
 	"crypto/aes"
-	"crypto/cipher"
-	"crypto/rand"
 	"fmt"
-)
-
-// generateAESKey generates an AES key, either 16, 24, or 32 bytes to select AES-128, AES-192, or AES-256.
-func generateAESKey(size int) ([]byte, error) {
-	if size != 16 && size != 24 && size != 32 {
-		return nil, fmt.Errorf("AES key size must be 16, 24 or 32 bytes")
-	}
-	key := make([]byte, size)
-	if _, err := rand.Read(key); err != nil {
-		return nil, err
-	}
-	return key, nil
-}
-
-// GenerateIV generates an IV (Initialization Vector). IV must match the block size of cipher used.
-func generateIV(size int) ([]byte, error) {
-	iv := make([]byte, size)
-	_, err := rand.Read(iv)
-	if err != nil {
-		return nil, err
-	}
-	return iv, nil
-}
-
-// pkcs7Pad adds padding to the end of message to make its length a multiple of 16.
-func pkcs7Pad(input []byte) []byte {
-	paddingSize := aes.BlockSize - len(input)%aes.BlockSize
-	padding := bytes.Repeat([]byte{byte(paddingSize)}, paddingSize)
-	return append(input, padding...)
-}
-
-// pkcs7UnPad removes padding from the decrypted data to obtain the original plaintext.
-// References: https://en.wikipedia.org/wiki/PKCS_7
-func pkcs7Unpad(input []byte) ([]byte, error) {
-	msgLength := len(input)
-	paddingSize := int(input[msgLength-1])
-
-	if paddingSize > msgLength || paddingSize == 0 {
-		return nil, fmt.Errorf("invalid padding")
-	}
-
-	for i := msgLength - 1; i <= msgLength-paddingSize; i++ {
-		if input[i] != byte(paddingSize) {
-			return nil, fmt.Errorf("invalid padding")
-		}
-	}
 
-	return input[:msgLength-paddingSize], nil
-}
+	"github.com/japananh/crypto/modes"
+)
 
-func encrypt(key, iv, plaintext []byte) ([]byte, error) {
-	blockCipher, err := aes.NewCipher(key)
+// encrypt encrypts plaintext using CBC mode with PKCS#7 padding and returns IV || ciphertext.
+func encrypt(key, plaintext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
 	if err != nil {
 		return nil, err
 	}
 
-	ciphertext := make([]byte, aes.BlockSize+len(plaintext))
-	copy(ciphertext[:aes.BlockSize], iv)
-
-	stream := cipher.NewCBCEncrypter(blockCipher, iv)
-	stream.CryptBlocks(ciphertext[aes.BlockSize:], plaintext)
-
-	return ciphertext, nil
+	return modes.NewCBC(block).Encrypt(plaintext)
 }
 
-func decrypt(key, iv, ciphertext []byte) ([]byte, error) {
+// decrypt decrypts IV || ciphertext and removes the padding.
+func decrypt(key, ciphertext []byte) ([]byte, error) {
 	block, err := aes.NewCipher(key)
 	if err != nil {
 		return nil, err
 	}
 
-	decryptedText := make([]byte, len(ciphertext))
-
-	mode := cipher.NewCBCDecrypter(block, iv)
-	mode.CryptBlocks(decryptedText, ciphertext)
-
-	return pkcs7Unpad(decryptedText[aes.BlockSize:])
+	return modes.NewCBC(block).Decrypt(ciphertext)
 }
 
 func main() {
 	// Generate an AES key, either 16, 24, or 32 bytes to select AES-128, AES-192, or AES-256
-	key, err := generateAESKey(16)
+	key, err := modes.GenerateAESKey(16)
 	if err != nil {
 		panic(err)
 	}
 
-	// The Initialization Vector (IV) must be the same size as the block size of the cipher being used.
-	// In this example, AES block size = 16 bytes = 128 bits
-	iv, err := generateIV(aes.BlockSize)
-	if err != nil {
-		panic(err)
-	}
-
-	// The message you want to encrypt.
+	// The message you want to encrypt. CBC pads it to a multiple of the block size
+	// and prepends a random IV of the same size as the block.
 	plaintext := []byte("This is a sample message to be encrypted using CBC mode with padding.")
 
-	// Add padding to the message.
-	padded := pkcs7Pad(plaintext)
-
 	// Encrypt the message.
-	ciphertext, err := encrypt(key, iv, padded)
+	ciphertext, err := encrypt(key, plaintext)
 	if err != nil {
 		panic(err)
 	}
@@ -114,7 +47,7 @@ func main() {
 	fmt.Printf("Encrypted message: %x\n", ciphertext)
 
 	// Decrypt the message.
-	decryptedMsg, err := decrypt(key, iv, ciphertext)
+	decryptedMsg, err := decrypt(key, ciphertext)
 	if err != nil {
 		panic(err)
 	}
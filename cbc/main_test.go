@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"testing"
+
+	paddingoracle "cryptography-challenge/3.17_cbc_padding_oracle"
+)
+
+func Test_EncryptDecrypt(t *testing.T) {
+	key := []byte("YELLOW SUBMARINE")
+
+	tests := []struct {
+		name      string
+		plaintext []byte
+	}{
+		{name: "Test empty message", plaintext: []byte{}},
+		{name: "Test whole block", plaintext: []byte("sixteen byte msg")},
+		{name: "Test partial block", plaintext: []byte("This is a sample message to be encrypted using CBC mode.")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ciphertext, err := encrypt(key, tt.plaintext)
+			if err != nil {
+				t.Fatalf("encrypt() error = %v", err)
+			}
+			got, err := decrypt(key, ciphertext)
+			if err != nil {
+				t.Fatalf("decrypt() error = %v", err)
+			}
+			if !bytes.Equal(got, tt.plaintext) {
+				t.Errorf("decrypt() got = %q, want %q", got, tt.plaintext)
+			}
+		})
+	}
+}
+
+func Test_DecryptTooShort(t *testing.T) {
+	if _, err := decrypt([]byte("YELLOW SUBMARINE"), make([]byte, 15)); err == nil {
+		t.Errorf("decrypt() error = nil, want error")
+	}
+}
+
+func Test_DecryptIsAPaddingOracle(t *testing.T) {
+	key := []byte("YELLOW SUBMARINE")
+	plaintext := []byte("decrypt tells bad padding apart from other errors, which is all the attack needs")
+
+	ciphertext, err := encrypt(key, plaintext)
+	if err != nil {
+		t.Fatalf("encrypt() error = %v", err)
+	}
+
+	// The attacker only learns whether decrypt failed with an invalid padding error
+	oracle := paddingoracle.NewDecryptOracle(decrypt, key)
+	got, err := paddingoracle.Decrypt(oracle, ciphertext[:aes.BlockSize], ciphertext[aes.BlockSize:], aes.BlockSize)
+	if err != nil {
+		t.Fatalf("paddingoracle.Decrypt() error = %v", err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Errorf("paddingoracle.Decrypt() got = %q, want %q", got, plaintext)
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_EncryptDecrypt(t *testing.T) {
+	key := []byte("YELLOW SUBMARINE")
+
+	tests := []struct {
+		name      string
+		plaintext []byte
+	}{
+		{name: "Test empty message", plaintext: []byte{}},
+		{name: "Test whole block", plaintext: []byte("sixteen byte msg")},
+		{name: "Test partial block", plaintext: []byte("This is a sample message to be encrypted using CFB mode.")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ciphertext, err := encrypt(key, tt.plaintext)
+			if err != nil {
+				t.Fatalf("encrypt() error = %v", err)
+			}
+			got, err := decrypt(key, ciphertext)
+			if err != nil {
+				t.Fatalf("decrypt() error = %v", err)
+			}
+			if !bytes.Equal(got, tt.plaintext) {
+				t.Errorf("decrypt() got = %q, want %q", got, tt.plaintext)
+			}
+		})
+	}
+}
+
+func Test_DecryptTooShort(t *testing.T) {
+	if _, err := decrypt([]byte("YELLOW SUBMARINE"), make([]byte, 15)); err == nil {
+		t.Errorf("decrypt() error = nil, want error")
+	}
+}
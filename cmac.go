@@ -0,0 +1,130 @@
+package crypto
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"fmt"
+	"hash"
+)
+
+// macBlockSize is the block size CMAC and PMAC are implemented for, the AES block size.
+// The subkey derivation constants below are only defined for 128-bit blocks.
+const macBlockSize = 16
+
+// cmac implements AES-CMAC as specified in RFC 4493 and NIST SP 800-38B.
+type cmac struct {
+	block  cipher.Block
+	k1, k2 [macBlockSize]byte
+
+	// state is the CBC-MAC chaining value of every block processed so far.
+	// The last block of the message is kept in buf until Sum is called, because it is masked with k1 or k2.
+	state [macBlockSize]byte
+	buf   [macBlockSize]byte
+	n     int
+}
+
+// NewCMAC returns a hash.Hash computing AES-CMAC (RFC 4493, NIST SP 800-38B) with the given block cipher.
+// The block cipher must have a 16-byte block size.
+func NewCMAC(b cipher.Block) (hash.Hash, error) {
+	if b.BlockSize() != macBlockSize {
+		return nil, fmt.Errorf("cmac: block size must be %d bytes, got %d", macBlockSize, b.BlockSize())
+	}
+
+	c := &cmac{block: b}
+	c.k1, c.k2 = cmacSubkeys(b)
+
+	return c, nil
+}
+
+// cmacSubkeys generates the CMAC subkeys: L = E(0^128), K1 = L·x and K2 = L·x^2 in GF(2^128).
+func cmacSubkeys(b cipher.Block) (k1, k2 [macBlockSize]byte) {
+	var l [macBlockSize]byte
+	b.Encrypt(l[:], l[:])
+
+	k1 = gfDouble(l)
+	k2 = gfDouble(k1)
+
+	return k1, k2
+}
+
+func (c *cmac) Write(p []byte) (int, error) {
+	written := len(p)
+
+	for len(p) > 0 {
+		// Only process the buffered block once more data arrives, so the final block is never processed here
+		if c.n == macBlockSize {
+			subtle.XORBytes(c.state[:], c.state[:], c.buf[:])
+			c.block.Encrypt(c.state[:], c.state[:])
+			c.n = 0
+		}
+
+		copied := copy(c.buf[c.n:], p)
+		c.n += copied
+		p = p[copied:]
+	}
+
+	return written, nil
+}
+
+func (c *cmac) Sum(b []byte) []byte {
+	last := c.buf
+
+	// A complete final block is masked with K1, an incomplete one is padded with 10* and masked with K2
+	if c.n == macBlockSize {
+		subtle.XORBytes(last[:], last[:], c.k1[:])
+	} else {
+		last[c.n] = 0x80
+		for i := c.n + 1; i < macBlockSize; i++ {
+			last[i] = 0
+		}
+		subtle.XORBytes(last[:], last[:], c.k2[:])
+	}
+
+	var tag [macBlockSize]byte
+	subtle.XORBytes(tag[:], c.state[:], last[:])
+	c.block.Encrypt(tag[:], tag[:])
+
+	return append(b, tag[:]...)
+}
+
+func (c *cmac) Reset() {
+	c.state = [macBlockSize]byte{}
+	c.buf = [macBlockSize]byte{}
+	c.n = 0
+}
+
+func (c *cmac) Size() int {
+	return macBlockSize
+}
+
+func (c *cmac) BlockSize() int {
+	return macBlockSize
+}
+
+// gfDouble multiplies a 128-bit block by x in GF(2^128), using the big-endian bit order of SP 800-38B
+// and the reduction polynomial x^128 + x^7 + x^2 + x + 1.
+func gfDouble(in [macBlockSize]byte) (out [macBlockSize]byte) {
+	carry := in[0] >> 7
+	for i := 0; i < macBlockSize-1; i++ {
+		out[i] = in[i]<<1 | in[i+1]>>7
+	}
+	out[macBlockSize-1] = in[macBlockSize-1] << 1
+	out[macBlockSize-1] ^= 0x87 * carry
+
+	return out
+}
+
+// gfHalve multiplies a 128-bit block by x^-1 in GF(2^128), the inverse of gfDouble.
+func gfHalve(in [macBlockSize]byte) (out [macBlockSize]byte) {
+	carry := in[macBlockSize-1] & 1
+	for i := macBlockSize - 1; i > 0; i-- {
+		out[i] = in[i]>>1 | in[i-1]<<7
+	}
+	out[0] = in[0] >> 1
+
+	// x^-1 = x^127 + x^6 + x + 1, i.e. 0x80000000000000000000000000000043
+	out[0] ^= 0x80 * carry
+	out[macBlockSize-1] ^= 0x43 * carry
+
+	return out
+}
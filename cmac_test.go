@@ -0,0 +1,102 @@
+package crypto_test
+
+import (
+	"crypto/aes"
+	"crypto/des"
+	"encoding/hex"
+
+	"github.com/japananh/crypto"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("crypto - cmac", func() {
+	decodeHex := func(s string) []byte {
+		b, err := hex.DecodeString(s)
+		Expect(err).NotTo(HaveOccurred())
+		return b
+	}
+
+	// Test vectors from RFC 4493, section 4
+	key := decodeHex("2b7e151628aed2a6abf7158809cf4f3c")
+	message := decodeHex("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e5130c81c46a35ce411e5fbc1191a0a52eff69f2445df4f9b17ad2b417be66c3710")
+
+	cmacTestVectors := []struct {
+		name   string
+		length int
+		tag    string
+	}{
+		{name: "Example 1: len = 0", length: 0, tag: "bb1d6929e95937287fa37d129b756746"},
+		{name: "Example 2: len = 16", length: 16, tag: "070a16b46b4d4144f79bdd9dd04a287c"},
+		{name: "Example 3: len = 40", length: 40, tag: "dfa66747de9ae63030ca32611497c827"},
+		{name: "Example 4: len = 64", length: 64, tag: "51f0bebf7e3b9d92fc49741779363cfe"},
+	}
+
+	It("should generate the RFC 4493 subkeys", func() {
+		block, err := aes.NewCipher(key)
+		Expect(err).NotTo(HaveOccurred())
+
+		k1, k2 := crypto.CMACSubkeys(block)
+
+		Expect(hex.EncodeToString(k1[:])).To(Equal("fbeed618357133667c85e08f7236a8de"))
+		Expect(hex.EncodeToString(k2[:])).To(Equal("f7ddac306ae266ccf90bc11ee46d513b"))
+	})
+
+	for _, tt := range cmacTestVectors {
+		tt := tt
+		It("should match RFC 4493 "+tt.name, func() {
+			block, err := aes.NewCipher(key)
+			Expect(err).NotTo(HaveOccurred())
+
+			mac, err := crypto.NewCMAC(block)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = mac.Write(message[:tt.length])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hex.EncodeToString(mac.Sum(nil))).To(Equal(tt.tag))
+		})
+	}
+
+	It("should give the same tag when the message is written in pieces", func() {
+		block, err := aes.NewCipher(key)
+		Expect(err).NotTo(HaveOccurred())
+
+		mac, err := crypto.NewCMAC(block)
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, piece := range [][]byte{message[:5], message[5:16], message[16:33], message[33:]} {
+			_, err := mac.Write(piece)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		Expect(hex.EncodeToString(mac.Sum(nil))).To(Equal("51f0bebf7e3b9d92fc49741779363cfe"))
+	})
+
+	It("should not change its state on Sum and start over on Reset", func() {
+		block, err := aes.NewCipher(key)
+		Expect(err).NotTo(HaveOccurred())
+
+		mac, err := crypto.NewCMAC(block)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, _ = mac.Write(message[:16])
+		Expect(hex.EncodeToString(mac.Sum(nil))).To(Equal("070a16b46b4d4144f79bdd9dd04a287c"))
+
+		_, _ = mac.Write(message[16:40])
+		Expect(hex.EncodeToString(mac.Sum(nil))).To(Equal("dfa66747de9ae63030ca32611497c827"))
+
+		mac.Reset()
+		Expect(hex.EncodeToString(mac.Sum(nil))).To(Equal("bb1d6929e95937287fa37d129b756746"))
+		Expect(mac.Size()).To(Equal(aes.BlockSize))
+		Expect(mac.BlockSize()).To(Equal(aes.BlockSize))
+	})
+
+	It("should reject a block cipher that does not have a 16-byte block", func() {
+		block, err := des.NewCipher(make([]byte, 8))
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = crypto.NewCMAC(block)
+		Expect(err).To(HaveOccurred())
+	})
+})
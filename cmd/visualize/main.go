@@ -0,0 +1,48 @@
+// Command visualize prints how a block cipher mode of operation encrypts a short message, block by block.
+//
+//	go run ./cmd/visualize -mode cbc -key "YELLOW SUBMARINE" -plaintext "Hello, block cipher modes!"
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/japananh/crypto/visualize"
+)
+
+func main() {
+	var modeNames []string
+	for _, m := range visualize.Modes {
+		modeNames = append(modeNames, string(m))
+	}
+
+	modeName := flag.String("mode", "cbc", "mode of operation: "+strings.Join(modeNames, ", "))
+	key := flag.String("key", "YELLOW SUBMARINE", "AES key of 16, 24 or 32 characters")
+	ivHex := flag.String("iv", "", "IV, or nonce for GCM, in hex (random if empty)")
+	plaintext := flag.String("plaintext", "Hello, block cipher modes!", "message to encrypt")
+	flag.Parse()
+
+	mode, err := visualize.ParseMode(*modeName)
+	if err != nil {
+		fail(err)
+	}
+
+	var iv []byte
+	if *ivHex != "" {
+		if iv, err = hex.DecodeString(*ivHex); err != nil {
+			fail(fmt.Errorf("invalid IV: %v", err))
+		}
+	}
+
+	if err := visualize.Trace(os.Stdout, mode, []byte(*key), iv, []byte(*plaintext)); err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
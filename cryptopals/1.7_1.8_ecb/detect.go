@@ -0,0 +1,69 @@
+package ecb
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const blockSize = 16
+
+// Candidate is a ciphertext scored by DetectECB.
+type Candidate struct {
+	// Line is the 1-based line number of the ciphertext in the input
+	Line int
+	// Ciphertext is the hex-encoded ciphertext
+	Ciphertext string
+	// Repetitions is the number of 16-byte blocks that repeat an earlier block of the same ciphertext
+	Repetitions int
+}
+
+// DetectECB scores every hex-encoded ciphertext line by its repeated 16-byte blocks and returns them ranked
+// from the most to the least repetitive. ECB encrypts identical plaintext blocks to identical ciphertext
+// blocks, whereas the blocks of a good cipher mode look random and almost never repeat, so the first
+// candidates are the most likely to be ECB. Empty lines are skipped.
+func DetectECB(ciphertexts string) ([]Candidate, error) {
+	var candidates []Candidate
+
+	for i, line := range strings.Split(ciphertexts, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		ciphertext, err := hex.DecodeString(line)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding line %d: %v", i+1, err)
+		}
+
+		candidates = append(candidates, Candidate{
+			Line:        i + 1,
+			Ciphertext:  line,
+			Repetitions: countRepeatedBlocks(ciphertext),
+		})
+	}
+
+	// Keep the input order between candidates with the same score
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Repetitions > candidates[j].Repetitions
+	})
+
+	return candidates, nil
+}
+
+// countRepeatedBlocks returns the number of complete blocks that are equal to an earlier block.
+func countRepeatedBlocks(ciphertext []byte) int {
+	seen := make(map[string]bool)
+	repetitions := 0
+
+	for i := 0; i+blockSize <= len(ciphertext); i += blockSize {
+		block := string(ciphertext[i : i+blockSize])
+		if seen[block] {
+			repetitions++
+		}
+		seen[block] = true
+	}
+
+	return repetitions
+}
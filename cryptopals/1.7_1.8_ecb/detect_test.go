@@ -0,0 +1,81 @@
+package ecb
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_DetectECB(t *testing.T) {
+	challenge, err := os.ReadFile("8.txt")
+	if err != nil {
+		t.Fatalf("error reading 8.txt: %v", err)
+	}
+
+	type args struct {
+		ciphertexts string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []Candidate
+		wantErr bool
+	}{
+		{
+			name: "Test DetectECB ranks lines by repeated blocks",
+			args: args{
+				ciphertexts: "00112233445566778899aabbccddeeff\n" +
+					"\n" +
+					"000102030405060708090a0b0c0d0e0f000102030405060708090a0b0c0d0e0f000102030405060708090a0b0c0d0e0f\n" +
+					"ffeeddccbbaa99887766554433221100ffeeddccbbaa99887766554433221100\n",
+			},
+			want: []Candidate{
+				{Line: 3, Ciphertext: "000102030405060708090a0b0c0d0e0f000102030405060708090a0b0c0d0e0f000102030405060708090a0b0c0d0e0f", Repetitions: 2},
+				{Line: 4, Ciphertext: "ffeeddccbbaa99887766554433221100ffeeddccbbaa99887766554433221100", Repetitions: 1},
+				{Line: 1, Ciphertext: "00112233445566778899aabbccddeeff", Repetitions: 0},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Test DetectECB with empty input",
+			args:    args{ciphertexts: ""},
+			want:    nil,
+			wantErr: false,
+		},
+		{
+			name:    "Test DetectECB with invalid hex",
+			args:    args{ciphertexts: "00112233\nnot hex\n"},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DetectECB(tt.args.ciphertexts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DetectECB() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DetectECB() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("Test DetectECB finds the challenge 8 ciphertext", func(t *testing.T) {
+		got, err := DetectECB(string(challenge))
+		if err != nil {
+			t.Fatalf("DetectECB() error = %v", err)
+		}
+		if len(got) != strings.Count(string(challenge), "\n") {
+			t.Errorf("DetectECB() got %d candidates, want one per line", len(got))
+		}
+		if got[0].Line != 133 || got[0].Repetitions != 3 {
+			t.Errorf("DetectECB() best candidate = line %d with %d repetitions, want line 133 with 3", got[0].Line, got[0].Repetitions)
+		}
+		if got[1].Repetitions != 0 {
+			t.Errorf("DetectECB() second candidate has %d repetitions, want 0", got[1].Repetitions)
+		}
+	})
+}
@@ -3,6 +3,7 @@ package ecb
 import (
 	"crypto/aes"
 	"encoding/base64"
+	"fmt"
 
 	"cryptography-challenge/utils"
 )
@@ -32,6 +33,9 @@ func Decrypt(ciphertext, key []byte) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	if len(ciphertext)%block.BlockSize() != 0 {
+		return nil, fmt.Errorf("ciphertext length must be a multiple of %d bytes, got %d", block.BlockSize(), len(ciphertext))
+	}
 
 	plaintext := make([]byte, len(ciphertext))
 
@@ -78,6 +78,15 @@ func Test_Decrypt(t *testing.T) {
 			want:    []byte{},
 			wantErr: false,
 		},
+		{
+			name: "Test Decrypt fails on a partial block",
+			args: args{
+				ciphertext: make([]byte, 20),
+				key:        []byte("YELLOW SUBMARINE"),
+			},
+			want:    nil,
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
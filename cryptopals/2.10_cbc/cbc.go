@@ -0,0 +1,76 @@
+package cbc
+
+import (
+	"crypto/aes"
+	"encoding/base64"
+	"fmt"
+
+	"cryptography-challenge/utils"
+)
+
+// Encrypt encrypts plaintext with AES in CBC mode, built from single-block ECB encryptions:
+// every plaintext block is XORed with the previous ciphertext block (the IV for the first one) before it is encrypted.
+func Encrypt(plaintext, key, iv []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != block.BlockSize() {
+		return nil, fmt.Errorf("IV length must be %d bytes, got %d", block.BlockSize(), len(iv))
+	}
+
+	paddedText := utils.Pkcs7Pad(plaintext)
+	ciphertext := make([]byte, len(paddedText))
+
+	prev := iv
+	for i := 0; i < len(paddedText); i += block.BlockSize() {
+		current := ciphertext[i : i+block.BlockSize()]
+		xor(current, paddedText[i:i+block.BlockSize()], prev)
+		block.Encrypt(current, current)
+		prev = current
+	}
+
+	return ciphertext, nil
+}
+
+// Decrypt decrypts AES-CBC ciphertext: every block is decrypted, then XORed with the previous ciphertext block.
+func Decrypt(ciphertext, key, iv []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != block.BlockSize() {
+		return nil, fmt.Errorf("IV length must be %d bytes, got %d", block.BlockSize(), len(iv))
+	}
+	if len(ciphertext) == 0 || len(ciphertext)%block.BlockSize() != 0 {
+		return nil, fmt.Errorf("ciphertext length must be a positive multiple of %d bytes, got %d", block.BlockSize(), len(ciphertext))
+	}
+
+	plaintext := make([]byte, len(ciphertext))
+
+	prev := iv
+	for i := 0; i < len(ciphertext); i += block.BlockSize() {
+		current := plaintext[i : i+block.BlockSize()]
+		block.Decrypt(current, ciphertext[i:i+block.BlockSize()])
+		xor(current, current, prev)
+		prev = ciphertext[i : i+block.BlockSize()]
+	}
+
+	return utils.Pkcs7Unpad(plaintext)
+}
+
+// DecryptFromBase64 decrypts base64-encoded AES-CBC ciphertext.
+func DecryptFromBase64(ciphertext string, key, iv []byte) ([]byte, error) {
+	ciphertextBytes, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	return Decrypt(ciphertextBytes, key, iv)
+}
+
+// xor sets dst to a XOR b, for a and b of the same length.
+func xor(dst, a, b []byte) {
+	for i := range a {
+		dst[i] = a[i] ^ b[i]
+	}
+}
@@ -0,0 +1,160 @@
+package cbc
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"cryptography-challenge/utils"
+)
+
+func Test_Encrypt(t *testing.T) {
+	key := []byte("YELLOW SUBMARINE")
+	iv := []byte("0123456789abcdef")
+
+	type args struct {
+		plaintext []byte
+		key       []byte
+		iv        []byte
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{
+			name:    "Test Encrypt run successfully",
+			args:    args{plaintext: []byte("This is a sample message to be encrypted using CBC mode."), key: key, iv: iv},
+			wantErr: false,
+		},
+		{
+			name:    "Test Encrypt whole block",
+			args:    args{plaintext: []byte("sixteen byte msg"), key: key, iv: iv},
+			wantErr: false,
+		},
+		{
+			name:    "Test Encrypt empty message",
+			args:    args{plaintext: []byte{}, key: key, iv: iv},
+			wantErr: false,
+		},
+		{
+			name:    "Test Encrypt with invalid key",
+			args:    args{plaintext: []byte("message"), key: []byte("short key"), iv: iv},
+			wantErr: true,
+		},
+		{
+			name:    "Test Encrypt with invalid IV",
+			args:    args{plaintext: []byte("message"), key: key, iv: []byte("short IV")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Encrypt(tt.args.plaintext, tt.args.key, tt.args.iv)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Encrypt() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+
+			// The hand-made CBC must match the standard library
+			block, err := aes.NewCipher(tt.args.key)
+			if err != nil {
+				t.Fatalf("aes.NewCipher() error = %v", err)
+			}
+			paddedText := utils.Pkcs7Pad(tt.args.plaintext)
+			want := make([]byte, len(paddedText))
+			cipher.NewCBCEncrypter(block, tt.args.iv).CryptBlocks(want, paddedText)
+
+			if !bytes.Equal(got, want) {
+				t.Errorf("Encrypt() got = %x, want %x", got, want)
+			}
+		})
+	}
+}
+
+func Test_Decrypt(t *testing.T) {
+	key := []byte("YELLOW SUBMARINE")
+	iv := []byte("0123456789abcdef")
+	plaintext := []byte("This is a sample message to be encrypted using CBC mode.")
+
+	ciphertext, err := Encrypt(plaintext, key, iv)
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+
+	type args struct {
+		ciphertext []byte
+		key        []byte
+		iv         []byte
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []byte
+		wantErr bool
+	}{
+		{
+			name:    "Test Decrypt run successfully",
+			args:    args{ciphertext: ciphertext, key: key, iv: iv},
+			want:    plaintext,
+			wantErr: false,
+		},
+		{
+			name:    "Test Decrypt with a partial block",
+			args:    args{ciphertext: ciphertext[:20], key: key, iv: iv},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "Test Decrypt empty ciphertext",
+			args:    args{ciphertext: []byte{}, key: key, iv: iv},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "Test Decrypt with invalid IV",
+			args:    args{ciphertext: ciphertext, key: key, iv: iv[:8]},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decrypt(tt.args.ciphertext, tt.args.key, tt.args.iv)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Decrypt() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decrypt() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_DecryptFromBase64(t *testing.T) {
+	challenge, err := os.ReadFile("10.txt")
+	if err != nil {
+		t.Fatalf("error reading 10.txt: %v", err)
+	}
+
+	got, err := DecryptFromBase64(string(challenge), []byte("YELLOW SUBMARINE"), make([]byte, aes.BlockSize))
+	if err != nil {
+		t.Fatalf("DecryptFromBase64() error = %v", err)
+	}
+
+	wantPrefix := "I'm back and I'm ringin' the bell \nA rockin' on the mike while the fly girls yell \n"
+	if !strings.HasPrefix(string(got), wantPrefix) {
+		t.Errorf("DecryptFromBase64() got = %q..., want prefix %q", got[:len(wantPrefix)], wantPrefix)
+	}
+	if !strings.HasSuffix(string(got), "Play that funky music \n") {
+		t.Errorf("DecryptFromBase64() got = ...%q, want the last line of the song", got[len(got)-30:])
+	}
+}
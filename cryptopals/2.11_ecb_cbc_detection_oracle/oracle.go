@@ -0,0 +1,116 @@
+package oracle
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	ecb "cryptography-challenge/1.7_1.8_ecb"
+	cbc "cryptography-challenge/2.10_cbc"
+)
+
+// Mode is the block cipher mode chosen by the encryption oracle.
+type Mode int
+
+const (
+	ModeECB Mode = iota
+	ModeCBC
+)
+
+func (m Mode) String() string {
+	switch m {
+	case ModeECB:
+		return "ECB"
+	case ModeCBC:
+		return "CBC"
+	default:
+		return fmt.Sprintf("Mode(%d)", int(m))
+	}
+}
+
+// EncryptionOracle encrypts input under a random AES key, after prepending and appending 5-10 random bytes,
+// with ECB or CBC (under a random IV) chosen at random. It also returns the chosen mode, so a detector can be checked.
+func EncryptionOracle(input []byte) ([]byte, Mode, error) {
+	key, err := randomBytes(aes.BlockSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	prefix, err := randomPadding()
+	if err != nil {
+		return nil, 0, err
+	}
+	suffix, err := randomPadding()
+	if err != nil {
+		return nil, 0, err
+	}
+	plaintext := append(append(prefix, input...), suffix...)
+
+	coin, err := randomInt(2)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if Mode(coin) == ModeECB {
+		ciphertext, err := ecb.Encrypt(plaintext, key)
+		return ciphertext, ModeECB, err
+	}
+
+	iv, err := randomBytes(aes.BlockSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	ciphertext, err := cbc.Encrypt(plaintext, key, iv)
+	return ciphertext, ModeCBC, err
+}
+
+// DetectMode tells whether encrypt uses ECB or CBC. It chooses the input: enough identical bytes to fill
+// two identical blocks whatever the length of the prefix, which ECB encrypts to two identical ciphertext blocks.
+func DetectMode(encrypt func(input []byte) ([]byte, error)) (Mode, error) {
+	// Up to 15 bytes complete the block of the prefix, then 2 blocks follow; a third block leaves a margin
+	input := bytes.Repeat([]byte{'A'}, 4*aes.BlockSize)
+
+	ciphertext, err := encrypt(input)
+	if err != nil {
+		return 0, err
+	}
+
+	candidates, err := ecb.DetectECB(hex.EncodeToString(ciphertext))
+	if err != nil {
+		return 0, err
+	}
+	if len(candidates) > 0 && candidates[0].Repetitions > 0 {
+		return ModeECB, nil
+	}
+
+	return ModeCBC, nil
+}
+
+// randomPadding returns 5 to 10 random bytes.
+func randomPadding() ([]byte, error) {
+	n, err := randomInt(6)
+	if err != nil {
+		return nil, err
+	}
+	return randomBytes(5 + n)
+}
+
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// randomInt returns a uniform random integer in [0, n).
+func randomInt(n int64) (int, error) {
+	i, err := rand.Int(rand.Reader, big.NewInt(n))
+	if err != nil {
+		return 0, err
+	}
+	return int(i.Int64()), nil
+}
@@ -0,0 +1,69 @@
+package oracle
+
+import (
+	"testing"
+)
+
+func Test_EncryptionOracle(t *testing.T) {
+	input := []byte("YELLOW SUBMARINE")
+	seen := map[Mode]bool{}
+
+	for i := 0; i < 200; i++ {
+		ciphertext, mode, err := EncryptionOracle(input)
+		if err != nil {
+			t.Fatalf("EncryptionOracle() error = %v", err)
+		}
+		// 16 bytes of input and 10 to 20 bytes of padding make 2 or 3 blocks once PKCS#7 padded
+		if len(ciphertext)%16 != 0 || len(ciphertext) < 32 || len(ciphertext) > 48 {
+			t.Fatalf("EncryptionOracle() ciphertext length = %d, want 32 to 48 bytes", len(ciphertext))
+		}
+		seen[mode] = true
+	}
+
+	if !seen[ModeECB] || !seen[ModeCBC] {
+		t.Errorf("EncryptionOracle() chose %v in 200 trials, want both ECB and CBC", seen)
+	}
+}
+
+func Test_DetectMode(t *testing.T) {
+	const trials = 1000
+	correct := 0
+
+	for i := 0; i < trials; i++ {
+		var used Mode
+		got, err := DetectMode(func(input []byte) ([]byte, error) {
+			ciphertext, mode, err := EncryptionOracle(input)
+			used = mode
+			return ciphertext, err
+		})
+		if err != nil {
+			t.Fatalf("DetectMode() error = %v", err)
+		}
+		if got == used {
+			correct++
+		}
+	}
+
+	if correct != trials {
+		t.Errorf("DetectMode() was right %d times out of %d, want 100%%", correct, trials)
+	}
+}
+
+func Test_ModeString(t *testing.T) {
+	tests := []struct {
+		name string
+		mode Mode
+		want string
+	}{
+		{name: "Test ECB", mode: ModeECB, want: "ECB"},
+		{name: "Test CBC", mode: ModeCBC, want: "CBC"},
+		{name: "Test unknown mode", mode: Mode(7), want: "Mode(7)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.mode.String(); got != tt.want {
+				t.Errorf("String() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
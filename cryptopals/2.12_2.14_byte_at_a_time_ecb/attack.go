@@ -0,0 +1,199 @@
+package byteatatime
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// maxBlockSize bounds the block sizes tried by DetectBlockSize.
+const maxBlockSize = 64
+
+// DetectBlockSize feeds the oracle longer and longer inputs: the ciphertext grows by one whole block
+// as soon as the input spills into a new block, and the size of that jump is the block size.
+func DetectBlockSize(o Oracle) (int, error) {
+	ciphertext, err := o.Encrypt(nil)
+	if err != nil {
+		return 0, err
+	}
+	initialLength := len(ciphertext)
+
+	for i := 1; i <= maxBlockSize; i++ {
+		ciphertext, err := o.Encrypt(bytes.Repeat([]byte{'A'}, i))
+		if err != nil {
+			return 0, err
+		}
+		if len(ciphertext) > initialLength {
+			return len(ciphertext) - initialLength, nil
+		}
+	}
+
+	return 0, fmt.Errorf("error detecting block size: ciphertext did not grow after %d bytes", maxBlockSize)
+}
+
+// IsECB reports whether the oracle encrypts in ECB mode: three blocks of identical input always contain two
+// aligned identical blocks, whatever the prefix, and only ECB encrypts them to identical ciphertext blocks.
+func IsECB(o Oracle, blockSize int) (bool, error) {
+	ciphertext, err := o.Encrypt(bytes.Repeat([]byte{'A'}, 3*blockSize))
+	if err != nil {
+		return false, err
+	}
+	_, ok := firstRepeatedBlock(ciphertext, blockSize)
+	return ok, nil
+}
+
+// DetectPrefixLength finds the length of the data the oracle puts before the input. It first finds the block
+// where the input starts, then how many input bytes it takes to fill that block: once the block is full,
+// changing the next input byte no longer changes it.
+func DetectPrefixLength(o Oracle, blockSize int) (int, error) {
+	start, err := firstInputBlock(o, blockSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for fill := 1; fill <= blockSize; fill++ {
+		x, err := o.Encrypt(append(bytes.Repeat([]byte{'A'}, fill), 'X'))
+		if err != nil {
+			return 0, err
+		}
+		y, err := o.Encrypt(append(bytes.Repeat([]byte{'A'}, fill), 'Y'))
+		if err != nil {
+			return 0, err
+		}
+		if bytes.Equal(block(x, start, blockSize), block(y, start, blockSize)) {
+			return (start+1)*blockSize - fill, nil
+		}
+	}
+
+	return 0, fmt.Errorf("error detecting prefix length: the input never fills a block")
+}
+
+// firstInputBlock returns the index of the block where the input starts: the first block that changes with the input.
+func firstInputBlock(o Oracle, blockSize int) (int, error) {
+	x, err := o.Encrypt([]byte{'X'})
+	if err != nil {
+		return 0, err
+	}
+	y, err := o.Encrypt([]byte{'Y'})
+	if err != nil {
+		return 0, err
+	}
+
+	for i := 0; (i+1)*blockSize <= len(x) && (i+1)*blockSize <= len(y); i++ {
+		if !bytes.Equal(block(x, i, blockSize), block(y, i, blockSize)) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("error detecting prefix length: the ciphertext does not depend on the input")
+}
+
+// DecryptSecret recovers the data the oracle appends to the input, one byte at a time.
+//
+// The input is chosen so that the next unknown byte is the last byte of a block whose other bytes are known.
+// Encrypting the known bytes followed by each of the 256 possible values gives a dictionary of that block,
+// and the value whose ciphertext block matches is the unknown byte.
+func DecryptSecret(o Oracle) ([]byte, error) {
+	blockSize, err := DetectBlockSize(o)
+	if err != nil {
+		return nil, err
+	}
+
+	ecb, err := IsECB(o, blockSize)
+	if err != nil {
+		return nil, err
+	}
+	if !ecb {
+		return nil, fmt.Errorf("error decrypting secret: the oracle does not use ECB mode")
+	}
+
+	prefixLength, err := DetectPrefixLength(o, blockSize)
+	if err != nil {
+		return nil, err
+	}
+	secretLength, err := detectSecretLength(o, blockSize, prefixLength)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fill the last block of the prefix, so the attack starts on a block boundary
+	alignment := (blockSize - prefixLength%blockSize) % blockSize
+	firstBlock := (prefixLength + alignment) / blockSize
+
+	secret := make([]byte, 0, secretLength)
+	for i := 0; i < secretLength; i++ {
+		// Push the secret so that byte i is the last byte of its block
+		shift := bytes.Repeat([]byte{'A'}, alignment+blockSize-1-i%blockSize)
+		target := firstBlock + i/blockSize
+
+		ciphertext, err := o.Encrypt(shift)
+		if err != nil {
+			return nil, err
+		}
+		want := block(ciphertext, target, blockSize)
+
+		// The block to guess holds the last blockSize-1 bytes of shift || secret[:i], then the unknown byte
+		known := append(shift[alignment:], secret...)
+		guess := make([]byte, alignment+blockSize)
+		copy(guess[alignment:], known[len(known)-(blockSize-1):])
+
+		found := false
+		for b := 0; b < 256; b++ {
+			guess[len(guess)-1] = byte(b)
+			ciphertext, err := o.Encrypt(guess)
+			if err != nil {
+				return nil, err
+			}
+			if bytes.Equal(block(ciphertext, firstBlock, blockSize), want) {
+				secret = append(secret, byte(b))
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("error decrypting secret: no match for byte %d", i)
+		}
+	}
+
+	return secret, nil
+}
+
+// detectSecretLength finds the length of the secret from the input length at which the ciphertext grows:
+// prefix || input || secret then exactly fills the blocks, and PKCS#7 adds a whole block of padding.
+func detectSecretLength(o Oracle, blockSize, prefixLength int) (int, error) {
+	ciphertext, err := o.Encrypt(nil)
+	if err != nil {
+		return 0, err
+	}
+	initialLength := len(ciphertext)
+
+	for i := 1; i <= blockSize; i++ {
+		ciphertext, err := o.Encrypt(bytes.Repeat([]byte{'A'}, i))
+		if err != nil {
+			return 0, err
+		}
+		if len(ciphertext) > initialLength {
+			// ecb.Encrypt returns an empty ciphertext for an empty plaintext instead of a block of padding,
+			// so without prefix and secret the length comes out as -1
+			secretLength := initialLength - i - prefixLength
+			if secretLength < 0 {
+				secretLength = 0
+			}
+			return secretLength, nil
+		}
+	}
+
+	return 0, fmt.Errorf("error detecting secret length: ciphertext did not grow after %d bytes", blockSize)
+}
+
+// firstRepeatedBlock returns the index of the first block equal to the block that follows it.
+func firstRepeatedBlock(ciphertext []byte, blockSize int) (int, bool) {
+	for i := 0; (i+2)*blockSize <= len(ciphertext); i++ {
+		if bytes.Equal(block(ciphertext, i, blockSize), block(ciphertext, i+1, blockSize)) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func block(data []byte, i, blockSize int) []byte {
+	return data[i*blockSize : (i+1)*blockSize]
+}
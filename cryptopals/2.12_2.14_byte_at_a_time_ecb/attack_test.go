@@ -0,0 +1,175 @@
+package byteatatime
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/base64"
+	"reflect"
+	"testing"
+
+	cbc "cryptography-challenge/2.10_cbc"
+)
+
+func mustDecodeSecret(t *testing.T) []byte {
+	secret, err := base64.StdEncoding.DecodeString(Secret)
+	if err != nil {
+		t.Fatalf("error decoding secret: %v", err)
+	}
+	return secret
+}
+
+// newOracle returns an ECB oracle with a fixed prefix and a random key.
+func newOracle(t *testing.T, prefix, secret []byte) *ECBOracle {
+	o, err := NewECBOracle(secret)
+	if err != nil {
+		t.Fatalf("NewECBOracle() error = %v", err)
+	}
+	o.prefix = prefix
+	return o
+}
+
+func Test_DetectBlockSize(t *testing.T) {
+	o, err := NewChallengeOracle(true)
+	if err != nil {
+		t.Fatalf("NewChallengeOracle() error = %v", err)
+	}
+
+	got, err := DetectBlockSize(o)
+	if err != nil {
+		t.Fatalf("DetectBlockSize() error = %v", err)
+	}
+	if got != aes.BlockSize {
+		t.Errorf("DetectBlockSize() got = %v, want %v", got, aes.BlockSize)
+	}
+}
+
+func Test_IsECB(t *testing.T) {
+	ecbOracle, err := NewChallengeOracle(true)
+	if err != nil {
+		t.Fatalf("NewChallengeOracle() error = %v", err)
+	}
+	cbcOracle := OracleFunc(func(input []byte) ([]byte, error) {
+		return cbc.Encrypt(append(input, "secret"...), []byte("YELLOW SUBMARINE"), []byte("0123456789abcdef"))
+	})
+
+	tests := []struct {
+		name   string
+		oracle Oracle
+		want   bool
+	}{
+		{name: "Test IsECB with ECB oracle", oracle: ecbOracle, want: true},
+		{name: "Test IsECB with CBC oracle", oracle: cbcOracle, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := IsECB(tt.oracle, aes.BlockSize)
+			if err != nil {
+				t.Fatalf("IsECB() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IsECB() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_DetectPrefixLength(t *testing.T) {
+	secret := mustDecodeSecret(t)
+
+	tests := []struct {
+		name   string
+		prefix []byte
+		secret []byte
+	}{
+		{name: "Test DetectPrefixLength without prefix", prefix: nil, secret: secret},
+		{name: "Test DetectPrefixLength with a partial block", prefix: []byte("random"), secret: secret},
+		{name: "Test DetectPrefixLength with a whole block", prefix: []byte("sixteen byte msg"), secret: secret},
+		{name: "Test DetectPrefixLength with several blocks", prefix: bytes.Repeat([]byte{0x42}, 37), secret: secret},
+		{name: "Test DetectPrefixLength with a prefix ending like the input", prefix: []byte("prefixAAA"), secret: secret},
+		{name: "Test DetectPrefixLength with a secret starting like the input", prefix: []byte("prefix"), secret: []byte("AAAsecret")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DetectPrefixLength(newOracle(t, tt.prefix, tt.secret), aes.BlockSize)
+			if err != nil {
+				t.Fatalf("DetectPrefixLength() error = %v", err)
+			}
+			if got != len(tt.prefix) {
+				t.Errorf("DetectPrefixLength() got = %v, want %v", got, len(tt.prefix))
+			}
+		})
+	}
+}
+
+func Test_DecryptSecret(t *testing.T) {
+	secret := mustDecodeSecret(t)
+
+	t.Run("Test DecryptSecret without prefix (challenge 12)", func(t *testing.T) {
+		o, err := NewChallengeOracle(false)
+		if err != nil {
+			t.Fatalf("NewChallengeOracle() error = %v", err)
+		}
+		got, err := DecryptSecret(o)
+		if err != nil {
+			t.Fatalf("DecryptSecret() error = %v", err)
+		}
+		if !reflect.DeepEqual(got, secret) {
+			t.Errorf("DecryptSecret() got = %q, want %q", got, secret)
+		}
+	})
+
+	t.Run("Test DecryptSecret with a random prefix (challenge 14)", func(t *testing.T) {
+		o, err := NewChallengeOracle(true)
+		if err != nil {
+			t.Fatalf("NewChallengeOracle() error = %v", err)
+		}
+		got, err := DecryptSecret(o)
+		if err != nil {
+			t.Fatalf("DecryptSecret() error = %v", err)
+		}
+		if !reflect.DeepEqual(got, secret) {
+			t.Errorf("DecryptSecret() got = %q, want %q", got, secret)
+		}
+	})
+
+	t.Run("Test DecryptSecret with every prefix alignment", func(t *testing.T) {
+		for n := 0; n <= 2*aes.BlockSize; n++ {
+			got, err := DecryptSecret(newOracle(t, bytes.Repeat([]byte{'p'}, n), []byte("short secret, 31 bytes long...")))
+			if err != nil {
+				t.Fatalf("DecryptSecret() with a %d-byte prefix error = %v", n, err)
+			}
+			if string(got) != "short secret, 31 bytes long..." {
+				t.Errorf("DecryptSecret() with a %d-byte prefix got = %q", n, got)
+			}
+		}
+	})
+
+	emptySecretTests := []struct {
+		name   string
+		prefix []byte
+	}{
+		{name: "Test DecryptSecret with an empty secret and no prefix", prefix: nil},
+		{name: "Test DecryptSecret with an empty secret and a prefix", prefix: []byte("prefix")},
+		{name: "Test DecryptSecret with an empty secret and a block-sized prefix", prefix: bytes.Repeat([]byte{'p'}, aes.BlockSize)},
+	}
+	for _, tt := range emptySecretTests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecryptSecret(newOracle(t, tt.prefix, nil))
+			if err != nil {
+				t.Fatalf("DecryptSecret() error = %v", err)
+			}
+			if len(got) != 0 {
+				t.Errorf("DecryptSecret() got = %q, want empty", got)
+			}
+		})
+	}
+
+	t.Run("Test DecryptSecret with a CBC oracle", func(t *testing.T) {
+		cbcOracle := OracleFunc(func(input []byte) ([]byte, error) {
+			return cbc.Encrypt(append(input, "secret"...), []byte("YELLOW SUBMARINE"), []byte("0123456789abcdef"))
+		})
+		if _, err := DecryptSecret(cbcOracle); err == nil {
+			t.Errorf("DecryptSecret() error = nil, want error")
+		}
+	})
+}
@@ -0,0 +1,95 @@
+package byteatatime
+
+import (
+	"crypto/aes"
+	"crypto/rand"
+	"encoding/base64"
+	"math/big"
+
+	ecb "cryptography-challenge/1.7_1.8_ecb"
+)
+
+// Secret is the unknown string of challenge 12, appended to the attacker input by the oracle.
+const Secret = "Um9sbGluJyBpbiBteSA1LjAKV2l0aCBteSByYWctdG9wIGRvd24gc28gbXkg" +
+	"aGFpciBjYW4gYmxvdwpUaGUgZ2lybGllcyBvbiBzdGFuZGJ5IHdhdmluZyBq" +
+	"dXN0IHRvIHNheSBoaQpEaWQgeW91IHN0b3A/IE5vLCBJIGp1c3QgZHJvdmUg" +
+	"YnkK"
+
+// maxPrefixLength bounds the random prefix of NewECBOracleWithPrefix.
+const maxPrefixLength = 64
+
+// Oracle encrypts attacker-controlled input together with data the attacker wants to recover.
+type Oracle interface {
+	Encrypt(input []byte) ([]byte, error)
+}
+
+// OracleFunc adapts an encryption function to the Oracle interface.
+type OracleFunc func(input []byte) ([]byte, error)
+
+func (f OracleFunc) Encrypt(input []byte) ([]byte, error) {
+	return f(input)
+}
+
+// ECBOracle computes AES-ECB(prefix || input || secret, key) under a key and prefix fixed at creation.
+type ECBOracle struct {
+	key    []byte
+	prefix []byte
+	secret []byte
+}
+
+// NewECBOracle returns the oracle of challenge 12: a random key and no prefix.
+func NewECBOracle(secret []byte) (*ECBOracle, error) {
+	key, err := randomBytes(aes.BlockSize)
+	if err != nil {
+		return nil, err
+	}
+	return &ECBOracle{key: key, secret: secret}, nil
+}
+
+// NewECBOracleWithPrefix returns the oracle of challenge 14: a random key and a random prefix
+// of random length, up to 64 bytes.
+func NewECBOracleWithPrefix(secret []byte) (*ECBOracle, error) {
+	o, err := NewECBOracle(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := rand.Int(rand.Reader, big.NewInt(maxPrefixLength+1))
+	if err != nil {
+		return nil, err
+	}
+	if o.prefix, err = randomBytes(int(n.Int64())); err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+// NewChallengeOracle returns an oracle hiding the Secret of challenge 12, with a random prefix if withPrefix is set.
+func NewChallengeOracle(withPrefix bool) (*ECBOracle, error) {
+	secret, err := base64.StdEncoding.DecodeString(Secret)
+	if err != nil {
+		return nil, err
+	}
+	if withPrefix {
+		return NewECBOracleWithPrefix(secret)
+	}
+	return NewECBOracle(secret)
+}
+
+func (o *ECBOracle) Encrypt(input []byte) ([]byte, error) {
+	plaintext := make([]byte, 0, len(o.prefix)+len(input)+len(o.secret))
+	plaintext = append(plaintext, o.prefix...)
+	plaintext = append(plaintext, input...)
+	plaintext = append(plaintext, o.secret...)
+
+	return ecb.Encrypt(plaintext, o.key)
+}
+
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
@@ -0,0 +1,49 @@
+package cutandpaste
+
+import (
+	"bytes"
+	"crypto/aes"
+	"fmt"
+)
+
+// MakeAdminProfile builds a valid encrypted profile with role=admin, only from profiles the oracle hands out
+// for chosen emails. ECB encrypts every block on its own, so blocks of different ciphertexts can be spliced:
+//
+//  1. an email that ends the first block with "email=AAAAAAAAAA" encrypts "admin" followed by its PKCS#7
+//     padding as a block of its own, a valid last block
+//  2. an email of the right length ends a block with "role=", so "user" and its padding fill the last block
+//  3. swapping that last block for the one of step 1 gives "...&role=admin"
+func MakeAdminProfile(o *ProfileOracle) ([]byte, error) {
+	const blockSize = aes.BlockSize
+	prefix := len("email=")
+
+	// Step 1: "email=" + filler completes the first block, then "admin" + padding is the second one
+	filler := bytes.Repeat([]byte{'A'}, blockSize-prefix)
+	adminBlock := append([]byte("admin"), bytes.Repeat([]byte{blockSize - 5}, blockSize-5)...)
+	ciphertext, err := o.EncryptedProfileFor(string(filler) + string(adminBlock))
+	if err != nil {
+		return nil, err
+	}
+	admin := ciphertext[blockSize : 2*blockSize]
+
+	// Step 2: grow the email so that "email=" + email + "&uid=10&role=" ends on a block boundary
+	head := len(ProfileFor("")) - len("user")
+	emailLength := (blockSize - head%blockSize) % blockSize
+	for emailLength < len("@bar.com")+1 {
+		emailLength += blockSize
+	}
+	email := string(bytes.Repeat([]byte{'a'}, emailLength-len("@bar.com"))) + "@bar.com"
+
+	ciphertext, err = o.EncryptedProfileFor(email)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) != head+emailLength+blockSize {
+		return nil, fmt.Errorf("error building admin profile: unexpected ciphertext length %d", len(ciphertext))
+	}
+
+	// Step 3: keep everything up to "role=" and append the admin block
+	forged := append(ciphertext[:len(ciphertext)-blockSize:len(ciphertext)-blockSize], admin...)
+
+	return forged, nil
+}
@@ -0,0 +1,51 @@
+package cutandpaste
+
+import (
+	"crypto/aes"
+	"crypto/rand"
+	"fmt"
+
+	ecb "cryptography-challenge/1.7_1.8_ecb"
+	"cryptography-challenge/kv"
+)
+
+// profileUID is the uid of every profile created by ProfileFor.
+const profileUID = "10"
+
+// ProfileFor encodes the user profile of an email address, with the "user" role:
+// "email=foo@bar.com&uid=10&role=user". The '&' and '=' metacharacters are removed from the email.
+func ProfileFor(email string) string {
+	return kv.Encode(kv.Values{
+		{Key: "email", Value: email},
+		{Key: "uid", Value: profileUID},
+		{Key: "role", Value: "user"},
+	}, '&')
+}
+
+// ProfileOracle hands out encrypted profiles and reads them back, under a random key the attacker does not know.
+type ProfileOracle struct {
+	key []byte
+}
+
+// NewProfileOracle returns a ProfileOracle with a random AES key.
+func NewProfileOracle() (*ProfileOracle, error) {
+	key := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return &ProfileOracle{key: key}, nil
+}
+
+// EncryptedProfileFor returns the profile of email encrypted with AES-ECB.
+func (o *ProfileOracle) EncryptedProfileFor(email string) ([]byte, error) {
+	return ecb.Encrypt([]byte(ProfileFor(email)), o.key)
+}
+
+// DecryptProfile decrypts and parses an encrypted profile.
+func (o *ProfileOracle) DecryptProfile(ciphertext []byte) (kv.Values, error) {
+	plaintext, err := ecb.Decrypt(ciphertext, o.key)
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting profile: %v", err)
+	}
+	return kv.Parse(string(plaintext), '&')
+}
@@ -0,0 +1,70 @@
+package cutandpaste
+
+import (
+	"testing"
+)
+
+func Test_ProfileFor(t *testing.T) {
+	tests := []struct {
+		name  string
+		email string
+		want  string
+	}{
+		{name: "Test ProfileFor run successfully", email: "foo@bar.com", want: "email=foo@bar.com&uid=10&role=user"},
+		{name: "Test ProfileFor removes metacharacters", email: "foo@bar.com&role=admin", want: "email=foo@bar.comroleadmin&uid=10&role=user"},
+		{name: "Test ProfileFor empty email", email: "", want: "email=&uid=10&role=user"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ProfileFor(tt.email); got != tt.want {
+				t.Errorf("ProfileFor() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ProfileOracle(t *testing.T) {
+	o, err := NewProfileOracle()
+	if err != nil {
+		t.Fatalf("NewProfileOracle() error = %v", err)
+	}
+
+	ciphertext, err := o.EncryptedProfileFor("foo@bar.com&role=admin")
+	if err != nil {
+		t.Fatalf("EncryptedProfileFor() error = %v", err)
+	}
+	profile, err := o.DecryptProfile(ciphertext)
+	if err != nil {
+		t.Fatalf("DecryptProfile() error = %v", err)
+	}
+	if role, _ := profile.Get("role"); role != "user" {
+		t.Errorf("DecryptProfile() role = %v, want user", role)
+	}
+
+	if _, err := o.DecryptProfile(ciphertext[:len(ciphertext)-1]); err == nil {
+		t.Errorf("DecryptProfile() of a truncated ciphertext error = nil, want error")
+	}
+}
+
+func Test_MakeAdminProfile(t *testing.T) {
+	o, err := NewProfileOracle()
+	if err != nil {
+		t.Fatalf("NewProfileOracle() error = %v", err)
+	}
+
+	ciphertext, err := MakeAdminProfile(o)
+	if err != nil {
+		t.Fatalf("MakeAdminProfile() error = %v", err)
+	}
+
+	profile, err := o.DecryptProfile(ciphertext)
+	if err != nil {
+		t.Fatalf("DecryptProfile() error = %v", err)
+	}
+	if role, _ := profile.Get("role"); role != "admin" {
+		t.Errorf("MakeAdminProfile() role = %q, want admin (profile %v)", role, profile)
+	}
+	if uid, _ := profile.Get("uid"); uid != "10" {
+		t.Errorf("MakeAdminProfile() uid = %q, want 10", uid)
+	}
+}
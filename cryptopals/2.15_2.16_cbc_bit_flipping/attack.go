@@ -0,0 +1,51 @@
+package bitflipping
+
+import (
+	"bytes"
+	"crypto/aes"
+	"fmt"
+)
+
+// injected is the plaintext block the attack writes, ending right before Suffix so every field stays well-formed.
+const injected = "AAAAA;admin=true"
+
+// maxAttempts bounds the number of cookies MakeAdminCookie tries.
+const maxAttempts = 20
+
+// MakeAdminCookie forges a cookie with admin=true, although the oracle quotes out ';' and '='.
+//
+// In CBC, P[i] = D(K, C[i]) XOR C[i-1]: flipping a bit of C[i-1] flips the same bit of P[i]. The attack sends two
+// blocks of user data it knows, and XORs the first ciphertext block with known XOR wanted to rewrite the second one.
+// The first block decrypts to garbage, which breaks the cookie when it happens to contain ';', so the attack
+// tries again with a fresh cookie.
+func MakeAdminCookie(o *Oracle) ([]byte, error) {
+	const blockSize = aes.BlockSize
+
+	// The user data starts on a block boundary: the IV, then the blocks of Prefix
+	scrambled := 1 + len(Prefix)/blockSize
+	if len(Prefix)%blockSize != 0 {
+		return nil, fmt.Errorf("error forging cookie: the prefix must fill whole blocks")
+	}
+
+	known := bytes.Repeat([]byte{'A'}, blockSize)
+	userdata := string(known) + string(known)
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		ciphertext, err := o.Encrypt(userdata)
+		if err != nil {
+			return nil, err
+		}
+
+		flipped := ciphertext[scrambled*blockSize : (scrambled+1)*blockSize]
+		for i := range flipped {
+			flipped[i] ^= known[i] ^ injected[i]
+		}
+
+		admin, err := o.IsAdmin(ciphertext)
+		if err == nil && admin {
+			return ciphertext, nil
+		}
+	}
+
+	return nil, fmt.Errorf("error forging cookie: no admin cookie after %d attempts", maxAttempts)
+}
@@ -0,0 +1,83 @@
+package bitflipping
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"cryptography-challenge/utils"
+)
+
+func Test_Encrypt(t *testing.T) {
+	o, err := NewOracle()
+	if err != nil {
+		t.Fatalf("NewOracle() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		userdata string
+	}{
+		{name: "Test Encrypt plain user data", userdata: "hello"},
+		{name: "Test Encrypt quotes out metacharacters", userdata: ";admin=true;"},
+		{name: "Test Encrypt quotes out metacharacters in a field", userdata: "x;admin=true"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ciphertext, err := o.Encrypt(tt.userdata)
+			if err != nil {
+				t.Fatalf("Encrypt() error = %v", err)
+			}
+			admin, err := o.IsAdmin(ciphertext)
+			if err != nil {
+				t.Fatalf("IsAdmin() error = %v", err)
+			}
+			if admin {
+				t.Errorf("IsAdmin() got = true for user data %q, want false", tt.userdata)
+			}
+		})
+	}
+}
+
+func Test_MakeAdminCookie(t *testing.T) {
+	o, err := NewOracle()
+	if err != nil {
+		t.Fatalf("NewOracle() error = %v", err)
+	}
+
+	for i := 0; i < 20; i++ {
+		ciphertext, err := MakeAdminCookie(o)
+		if err != nil {
+			t.Fatalf("MakeAdminCookie() error = %v", err)
+		}
+		admin, err := o.IsAdmin(ciphertext)
+		if err != nil || !admin {
+			t.Fatalf("IsAdmin() got = %v, %v, want true", admin, err)
+		}
+	}
+}
+
+func Test_IsAdminBadPadding(t *testing.T) {
+	o, err := NewOracle()
+	if err != nil {
+		t.Fatalf("NewOracle() error = %v", err)
+	}
+	ciphertext, err := o.Encrypt(strings.Repeat("A", 10))
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+
+	// Flipping the last byte of the second to last block changes the last padding byte
+	tampered := append([]byte(nil), ciphertext...)
+	tampered[len(tampered)-17] ^= 0x40
+
+	_, err = o.IsAdmin(tampered)
+	var paddingErr *utils.PaddingError
+	if !errors.As(err, &paddingErr) {
+		t.Errorf("IsAdmin() error = %v, want *utils.PaddingError", err)
+	}
+
+	if _, err := o.IsAdmin(ciphertext[:len(ciphertext)-3]); err == nil {
+		t.Errorf("IsAdmin() with a partial block error = nil, want error")
+	}
+}
@@ -0,0 +1,78 @@
+package bitflipping
+
+import (
+	"crypto/aes"
+	"crypto/rand"
+	"fmt"
+	"strings"
+
+	cbc "cryptography-challenge/2.10_cbc"
+	"cryptography-challenge/kv"
+)
+
+const (
+	// Prefix and Suffix surround the user data in every encrypted cookie
+	Prefix = "comment1=cooking%20MCs;userdata="
+	Suffix = ";comment2=%20like%20a%20pound%20of%20bacon"
+)
+
+// quoter quotes out the metacharacters of the cookie, so user data cannot add fields of its own.
+var quoter = strings.NewReplacer(";", "%3B", "=", "%3D")
+
+// Oracle encrypts cookies holding user data with AES-CBC, and tells whether a cookie grants admin rights.
+type Oracle struct {
+	key []byte
+}
+
+// NewOracle returns an Oracle with a random AES key.
+func NewOracle() (*Oracle, error) {
+	key, err := randomBytes(aes.BlockSize)
+	if err != nil {
+		return nil, err
+	}
+	return &Oracle{key: key}, nil
+}
+
+// Encrypt encrypts Prefix || quoted userdata || Suffix under a random IV and returns IV || ciphertext.
+func (o *Oracle) Encrypt(userdata string) ([]byte, error) {
+	iv, err := randomBytes(aes.BlockSize)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := cbc.Encrypt([]byte(Prefix+quoter.Replace(userdata)+Suffix), o.key, iv)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(iv, ciphertext...), nil
+}
+
+// IsAdmin decrypts IV || ciphertext and reports whether the cookie has an admin=true field.
+// A cookie with bad padding fails with a *utils.PaddingError.
+func (o *Oracle) IsAdmin(ciphertext []byte) (bool, error) {
+	if len(ciphertext) < aes.BlockSize {
+		return false, fmt.Errorf("ciphertext too short")
+	}
+
+	plaintext, err := cbc.Decrypt(ciphertext[aes.BlockSize:], o.key, ciphertext[:aes.BlockSize])
+	if err != nil {
+		return false, err
+	}
+
+	values, err := kv.Parse(string(plaintext), ';')
+	if err != nil {
+		return false, err
+	}
+
+	admin, _ := values.Get("admin")
+	return admin == "true", nil
+}
+
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
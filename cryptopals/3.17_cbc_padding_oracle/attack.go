@@ -0,0 +1,116 @@
+package paddingoracle
+
+import (
+	"fmt"
+
+	"cryptography-challenge/utils"
+)
+
+// PaddingOracle tells whether a CBC ciphertext decrypts to valid PKCS#7 padding. Anything that leaks this bit,
+// through an error message, a status code or a timing difference, is enough to decrypt the whole ciphertext.
+type PaddingOracle interface {
+	// ValidPadding reports whether ciphertext, encrypted under iv, has valid padding. An error means the oracle
+	// could not answer, not that the padding is wrong.
+	ValidPadding(iv, ciphertext []byte) (bool, error)
+}
+
+// PaddingOracleFunc adapts a function to the PaddingOracle interface.
+type PaddingOracleFunc func(iv, ciphertext []byte) (bool, error)
+
+func (f PaddingOracleFunc) ValidPadding(iv, ciphertext []byte) (bool, error) {
+	return f(iv, ciphertext)
+}
+
+// Decrypt decrypts a CBC ciphertext block by block with a padding oracle, and removes the padding.
+func Decrypt(o PaddingOracle, iv, ciphertext []byte, blockSize int) ([]byte, error) {
+	if len(iv) != blockSize {
+		return nil, fmt.Errorf("IV length must be %d bytes, got %d", blockSize, len(iv))
+	}
+	if len(ciphertext) == 0 || len(ciphertext)%blockSize != 0 {
+		return nil, fmt.Errorf("ciphertext length must be a positive multiple of %d bytes, got %d", blockSize, len(ciphertext))
+	}
+
+	plaintext := make([]byte, 0, len(ciphertext))
+
+	prev := iv
+	for i := 0; i < len(ciphertext); i += blockSize {
+		block := ciphertext[i : i+blockSize]
+
+		p, err := DecryptBlock(o, prev, block)
+		if err != nil {
+			return nil, fmt.Errorf("error decrypting block %d: %v", i/blockSize, err)
+		}
+		plaintext = append(plaintext, p...)
+
+		prev = block
+	}
+
+	return utils.Pkcs7Validate(plaintext)
+}
+
+// DecryptBlock decrypts a single ciphertext block that follows prev in the ciphertext (prev is the IV for the first block).
+//
+// The oracle is asked about the two-block ciphertext forged || block, which decrypts to D(K, block) XOR forged.
+// The last byte of forged is tried with every value until the padding is valid: the plaintext then ends with 0x01,
+// which reveals the last byte of D(K, block). Setting the known bytes so they decrypt to 0x02 then uncovers the
+// byte before, and so on. XORing D(K, block) with the real prev gives the plaintext.
+func DecryptBlock(o PaddingOracle, prev, block []byte) ([]byte, error) {
+	blockSize := len(block)
+	if len(prev) != blockSize {
+		return nil, fmt.Errorf("previous block length must be %d bytes, got %d", blockSize, len(prev))
+	}
+
+	// intermediate is D(K, block), recovered from the last byte to the first
+	intermediate := make([]byte, blockSize)
+	forged := make([]byte, blockSize)
+
+	for pos := blockSize - 1; pos >= 0; pos-- {
+		padValue := byte(blockSize - pos)
+
+		// The bytes after pos must decrypt to the padding value
+		for j := pos + 1; j < blockSize; j++ {
+			forged[j] = intermediate[j] ^ padValue
+		}
+
+		found := false
+		for guess := 0; guess < 256; guess++ {
+			forged[pos] = byte(guess)
+
+			valid, err := o.ValidPadding(forged, block)
+			if err != nil {
+				return nil, err
+			}
+			if !valid {
+				continue
+			}
+
+			// For the last byte, valid padding may also be 0x02 0x02 (or longer) if the byte before happens to
+			// decrypt to 0x02. Changing that byte breaks such a padding, but not a single 0x01
+			if pos == blockSize-1 && pos > 0 {
+				forged[pos-1] ^= 0xff
+				valid, err = o.ValidPadding(forged, block)
+				forged[pos-1] ^= 0xff
+				if err != nil {
+					return nil, err
+				}
+				if !valid {
+					continue
+				}
+			}
+
+			intermediate[pos] = byte(guess) ^ padValue
+			found = true
+			break
+		}
+		if !found {
+			return nil, fmt.Errorf("no valid padding for byte %d", pos)
+		}
+	}
+
+	plaintext := make([]byte, blockSize)
+	for i := range plaintext {
+		plaintext[i] = intermediate[i] ^ prev[i]
+	}
+
+	return plaintext, nil
+}
@@ -0,0 +1,22 @@
+package paddingoracle
+
+import (
+	"errors"
+
+	"github.com/japananh/crypto/padding"
+)
+
+// DecryptFunc has the signature of decrypt in the cbc demo: it decrypts IV || ciphertext under key.
+type DecryptFunc func(key, ciphertext []byte) ([]byte, error)
+
+// NewDecryptOracle turns decrypt into a padding oracle: a padding.ErrInvalidPadding error means invalid padding.
+// The cbc demo tests its own decrypt with it, to show that its distinguishable error is all an attacker needs.
+func NewDecryptOracle(decrypt DecryptFunc, key []byte) PaddingOracle {
+	return PaddingOracleFunc(func(iv, ciphertext []byte) (bool, error) {
+		_, err := decrypt(key, append(append([]byte{}, iv...), ciphertext...))
+		if errors.Is(err, padding.ErrInvalidPadding) {
+			return false, nil
+		}
+		return err == nil, err
+	})
+}
@@ -0,0 +1,200 @@
+package paddingoracle
+
+import (
+	"crypto/aes"
+	"encoding/base64"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/japananh/crypto/modes"
+)
+
+func Test_Decrypt(t *testing.T) {
+	s, err := NewServer()
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+
+	for _, secret := range Secrets {
+		want, err := base64.StdEncoding.DecodeString(secret)
+		if err != nil {
+			t.Fatalf("error decoding secret: %v", err)
+		}
+
+		t.Run(string(want[:6]), func(t *testing.T) {
+			iv, ciphertext, err := s.Encrypt(want)
+			if err != nil {
+				t.Fatalf("Encrypt() error = %v", err)
+			}
+
+			got, err := Decrypt(s, iv, ciphertext, aes.BlockSize)
+			if err != nil {
+				t.Fatalf("Decrypt() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Decrypt() got = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+// decryptCBC decrypts IV || ciphertext with modes.NewCBC, which reports bad padding with padding.ErrInvalidPadding.
+func decryptCBC(key, ciphertext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return modes.NewCBC(block).Decrypt(ciphertext)
+}
+
+func Test_DecryptWithDecryptOracle(t *testing.T) {
+	key, err := modes.GenerateAESKey(aes.BlockSize)
+	if err != nil {
+		t.Fatalf("GenerateAESKey() error = %v", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatalf("aes.NewCipher() error = %v", err)
+	}
+	oracle := NewDecryptOracle(decryptCBC, key)
+
+	for _, secret := range Secrets {
+		want, err := base64.StdEncoding.DecodeString(secret)
+		if err != nil {
+			t.Fatalf("error decoding secret: %v", err)
+		}
+
+		t.Run(string(want[:6]), func(t *testing.T) {
+			// Framed like the ciphertexts of the cbc demo: IV || ciphertext
+			encrypted, err := modes.NewCBC(block).Encrypt(want)
+			if err != nil {
+				t.Fatalf("Encrypt() error = %v", err)
+			}
+
+			got, err := Decrypt(oracle, encrypted[:aes.BlockSize], encrypted[aes.BlockSize:], aes.BlockSize)
+			if err != nil {
+				t.Fatalf("Decrypt() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Decrypt() got = %q, want %q", got, want)
+			}
+		})
+	}
+
+	// Errors other than bad padding are not answers of the oracle
+	if _, err := NewDecryptOracle(decryptCBC, []byte("short key")).ValidPadding(make([]byte, aes.BlockSize), make([]byte, aes.BlockSize)); err == nil {
+		t.Errorf("ValidPadding() with an invalid key error = nil, want error")
+	}
+}
+
+func Test_DecryptToken(t *testing.T) {
+	s, err := NewServer()
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+	iv, ciphertext, err := s.Token()
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+
+	got, err := Decrypt(s, iv, ciphertext, aes.BlockSize)
+	if err != nil {
+		t.Fatalf("Decrypt() error = %v", err)
+	}
+
+	found := false
+	for _, secret := range Secrets {
+		if secret == base64.StdEncoding.EncodeToString(got) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Decrypt() got = %q, want one of the secrets", got)
+	}
+}
+
+func Test_DecryptBlockFalsePositive(t *testing.T) {
+	s, err := NewServer()
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+	block, err := aes.NewCipher(s.key)
+	if err != nil {
+		t.Fatalf("aes.NewCipher() error = %v", err)
+	}
+
+	// Find ciphertext blocks whose second to last intermediate byte is 0x02: with a zero forged block, both
+	// 0x01 and 0x02 0x02 are then valid paddings while searching for the last byte
+	for found := 0; found < 5; {
+		c, err := randomBytes(aes.BlockSize)
+		if err != nil {
+			t.Fatalf("randomBytes() error = %v", err)
+		}
+		intermediate := make([]byte, aes.BlockSize)
+		block.Decrypt(intermediate, c)
+		if intermediate[aes.BlockSize-2] != 0x02 {
+			continue
+		}
+		found++
+
+		prev := make([]byte, aes.BlockSize)
+		got, err := DecryptBlock(s, prev, c)
+		if err != nil {
+			t.Fatalf("DecryptBlock() error = %v", err)
+		}
+		if !reflect.DeepEqual(got, intermediate) {
+			t.Errorf("DecryptBlock() got = %x, want %x", got, intermediate)
+		}
+	}
+}
+
+func Test_DecryptOverHTTP(t *testing.T) {
+	s, err := NewServer()
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	o := &HTTPOracle{URL: ts.URL, Client: ts.Client()}
+
+	want := []byte("000003Cooking MC's like a pound of bacon")
+	iv, ciphertext, err := s.Encrypt(want)
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+
+	valid, err := o.ValidPadding(iv, ciphertext)
+	if err != nil || !valid {
+		t.Fatalf("ValidPadding() got = %v, %v, want true", valid, err)
+	}
+
+	got, err := Decrypt(o, iv, ciphertext, aes.BlockSize)
+	if err != nil {
+		t.Fatalf("Decrypt() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Decrypt() got = %q, want %q", got, want)
+	}
+}
+
+func Test_HTTPOracleErrors(t *testing.T) {
+	s, err := NewServer()
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	o := &HTTPOracle{URL: ts.URL, Client: ts.Client()}
+
+	// A partial block is malformed, not a padding error: the server answers 400
+	if _, err := o.ValidPadding(make([]byte, aes.BlockSize), make([]byte, 5)); err == nil {
+		t.Errorf("ValidPadding() with a partial block error = nil, want error")
+	}
+
+	if _, err := Decrypt(o, make([]byte, 8), make([]byte, aes.BlockSize), aes.BlockSize); err == nil {
+		t.Errorf("Decrypt() with a short IV error = nil, want error")
+	}
+}
@@ -0,0 +1,144 @@
+package paddingoracle
+
+import (
+	"crypto/aes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+
+	cbc "cryptography-challenge/2.10_cbc"
+	"cryptography-challenge/utils"
+)
+
+// Secrets are the strings of challenge 17, one of which is encrypted in every token.
+var Secrets = []string{
+	"MDAwMDAwTm93IHRoYXQgdGhlIHBhcnR5IGlzIGp1bXBpbmc=",
+	"MDAwMDAxV2l0aCB0aGUgYmFzcyBraWNrZWQgaW4gYW5kIHRoZSBWZWdhJ3MgYXJlIHB1bXBpbic=",
+	"MDAwMDAyUXVpY2sgdG8gdGhlIHBvaW50LCB0byB0aGUgcG9pbnQsIG5vIGZha2luZw==",
+	"MDAwMDAzQ29va2luZyBNQydzIGxpa2UgYSBwb3VuZCBvZiBiYWNvbg==",
+	"MDAwMDA0QnVybmluZyAnZW0sIGlmIHlvdSBhaW4ndCBxdWljayBhbmQgbmltYmxl",
+	"MDAwMDA1SSBnbyBjcmF6eSB3aGVuIEkgaGVhciBhIGN5bWJhbA==",
+	"MDAwMDA2QW5kIGEgaGlnaCBoYXQgd2l0aCBhIHNvdXBlZCB1cCB0ZW1wbw==",
+	"MDAwMDA3SSdtIG9uIGEgcm9sbCwgaXQncyB0aW1lIHRvIGdvIHNvbG8=",
+	"MDAwMDA4b2xsaW4nIGluIG15IGZpdmUgcG9pbnQgb2g=",
+	"MDAwMDA5aXRoIG15IHJhZy10b3AgZG93biBzbyBteSBoYWlyIGNhbiBibG93",
+}
+
+// Server hands out CBC-encrypted tokens and checks them, under a key the attacker does not know.
+// It leaks the validity of the padding, which makes it a padding oracle: in process through ValidPadding,
+// and over HTTP through the status code of ServeHTTP.
+type Server struct {
+	key []byte
+}
+
+// NewServer returns a Server with a random AES key.
+func NewServer() (*Server, error) {
+	key, err := randomBytes(aes.BlockSize)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{key: key}, nil
+}
+
+// Token encrypts one of the Secrets, chosen at random, under a random IV.
+func (s *Server) Token() (iv, ciphertext []byte, err error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(len(Secrets))))
+	if err != nil {
+		return nil, nil, err
+	}
+	plaintext, err := base64.StdEncoding.DecodeString(Secrets[n.Int64()])
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.Encrypt(plaintext)
+}
+
+// Encrypt encrypts plaintext under a random IV.
+func (s *Server) Encrypt(plaintext []byte) (iv, ciphertext []byte, err error) {
+	if iv, err = randomBytes(aes.BlockSize); err != nil {
+		return nil, nil, err
+	}
+	if ciphertext, err = cbc.Encrypt(plaintext, s.key, iv); err != nil {
+		return nil, nil, err
+	}
+	return iv, ciphertext, nil
+}
+
+// ValidPadding decrypts the ciphertext and reports whether its padding is valid.
+func (s *Server) ValidPadding(iv, ciphertext []byte) (bool, error) {
+	_, err := cbc.Decrypt(ciphertext, s.key, iv)
+
+	var paddingErr *utils.PaddingError
+	if errors.As(err, &paddingErr) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// ServeHTTP checks the token in the "token" query parameter, the hex encoding of IV || ciphertext.
+// It answers 200 for a valid token, 403 for bad padding and 400 for a malformed request.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token, err := hex.DecodeString(r.URL.Query().Get("token"))
+	if err != nil || len(token) < aes.BlockSize {
+		http.Error(w, "malformed token", http.StatusBadRequest)
+		return
+	}
+
+	valid, err := s.ValidPadding(token[:aes.BlockSize], token[aes.BlockSize:])
+	switch {
+	case err != nil:
+		http.Error(w, "malformed token", http.StatusBadRequest)
+	case !valid:
+		http.Error(w, "invalid padding", http.StatusForbidden)
+	default:
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// HTTPOracle is a PaddingOracle that asks a remote Server over HTTP.
+type HTTPOracle struct {
+	// URL is the address of the endpoint, without the query string
+	URL string
+	// Client sends the requests; http.DefaultClient is used if it is nil
+	Client *http.Client
+}
+
+func (o *HTTPOracle) ValidPadding(iv, ciphertext []byte) (bool, error) {
+	client := o.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Get(o.URL + "?token=" + hex.EncodeToString(append(append([]byte(nil), iv...), ciphertext...)))
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	// Drain the body so the connection is reused for the thousands of requests of an attack
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		return false, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusForbidden:
+		return false, nil
+	default:
+		return false, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+}
+
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
@@ -0,0 +1,177 @@
+package fixednoncectr
+
+import (
+	"fmt"
+	"strings"
+
+	singlebytexorcipher "cryptography-challenge/1.3_single_byte_xor_cipher"
+)
+
+// BreakFixedNonce recovers the plaintexts of ciphertexts encrypted under the same key and nonce (challenge 20).
+//
+// They all share one keystream, so once truncated to the length of the shortest one, their concatenation is a
+// repeating-key XOR with a key as long as that length. Transposing it gives one single-byte XOR per key byte,
+// each broken by letter frequencies. It returns the recovered keystream and the truncated plaintexts.
+func BreakFixedNonce(ciphertexts [][]byte) (keystream []byte, plaintexts [][]byte, err error) {
+	if len(ciphertexts) == 0 {
+		return nil, nil, fmt.Errorf("error input must not be empty")
+	}
+
+	length := len(ciphertexts[0])
+	for _, c := range ciphertexts {
+		if len(c) < length {
+			length = len(c)
+		}
+	}
+	if length == 0 {
+		return nil, nil, fmt.Errorf("error ciphertexts must not be empty")
+	}
+
+	// Transpose: column i holds byte i of every ciphertext, all XORed with keystream byte i
+	columns := make([][]byte, length)
+	for _, c := range ciphertexts {
+		for i := 0; i < length; i++ {
+			columns[i] = append(columns[i], c[i])
+		}
+	}
+
+	keystream = make([]byte, length)
+	for i, column := range columns {
+		_, k, err := singlebytexorcipher.Crack(column, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		keystream[i] = k
+	}
+
+	plaintexts = make([][]byte, len(ciphertexts))
+	for i, c := range ciphertexts {
+		plaintexts[i], _ = singlebytexorcipher.Xor(c[:length], keystream)
+	}
+
+	return keystream, plaintexts, nil
+}
+
+// CribSolver recovers the keystream shared by ciphertexts encrypted under the same key and nonce by guessing
+// their plaintext (challenge 19). Every guess of a plaintext fragment, a crib, gives the keystream bytes under it,
+// which decrypt the same positions of every other ciphertext: a right guess makes them all readable, a wrong one
+// turns them into garbage. Guesses can be refined and undone interactively, starting from a statistical guess.
+type CribSolver struct {
+	ciphertexts [][]byte
+	keystream   []byte
+	known       []bool
+}
+
+// NewCribSolver returns a CribSolver that knows nothing of the keystream yet.
+func NewCribSolver(ciphertexts [][]byte) *CribSolver {
+	length := 0
+	for _, c := range ciphertexts {
+		if len(c) > length {
+			length = len(c)
+		}
+	}
+
+	return &CribSolver{
+		ciphertexts: ciphertexts,
+		keystream:   make([]byte, length),
+		known:       make([]bool, length),
+	}
+}
+
+// GuessFromStatistics guesses every keystream byte that at least minCiphertexts ciphertexts are long enough to
+// cover, by breaking the column of those bytes as a single-byte XOR. It is a starting point: the last columns
+// are covered by few ciphertexts, and letter frequencies often confuse the case of the first letters.
+func (s *CribSolver) GuessFromStatistics(minCiphertexts int) error {
+	for i := range s.keystream {
+		var column []byte
+		for _, c := range s.ciphertexts {
+			if i < len(c) {
+				column = append(column, c[i])
+			}
+		}
+		if len(column) < minCiphertexts || len(column) == 0 {
+			continue
+		}
+
+		_, k, err := singlebytexorcipher.Crack(column, nil)
+		if err != nil {
+			return err
+		}
+		s.keystream[i] = k
+		s.known[i] = true
+	}
+
+	return nil
+}
+
+// Guess sets the plaintext of ciphertext index at the given offset to crib, and derives the keystream under it.
+func (s *CribSolver) Guess(index, offset int, crib string) error {
+	if index < 0 || index >= len(s.ciphertexts) {
+		return fmt.Errorf("error ciphertext index %d out of range", index)
+	}
+	c := s.ciphertexts[index]
+	if offset < 0 || offset+len(crib) > len(c) {
+		return fmt.Errorf("error crib %q at offset %d does not fit in ciphertext %d of %d bytes", crib, offset, index, len(c))
+	}
+
+	for i := range crib {
+		s.keystream[offset+i] = c[offset+i] ^ crib[i]
+		s.known[offset+i] = true
+	}
+
+	return nil
+}
+
+// Forget marks n keystream bytes from offset as unknown again, to undo a wrong guess.
+func (s *CribSolver) Forget(offset, n int) {
+	for i := offset; i < offset+n && i < len(s.known); i++ {
+		if i >= 0 {
+			s.keystream[i] = 0
+			s.known[i] = false
+		}
+	}
+}
+
+// Keystream returns the keystream recovered so far, and which of its bytes are known.
+func (s *CribSolver) Keystream() ([]byte, []bool) {
+	return append([]byte(nil), s.keystream...), append([]bool(nil), s.known...)
+}
+
+// Plaintext returns ciphertext index decrypted with the keystream recovered so far, and whether every byte is known.
+func (s *CribSolver) Plaintext(index int) ([]byte, bool) {
+	c := s.ciphertexts[index]
+	plaintext := make([]byte, len(c))
+	complete := true
+
+	for i := range c {
+		plaintext[i] = c[i] ^ s.keystream[i]
+		if !s.known[i] {
+			complete = false
+		}
+	}
+
+	return plaintext, complete
+}
+
+// String shows every plaintext on a numbered line, with '_' for unknown bytes and '?' for unprintable ones.
+func (s *CribSolver) String() string {
+	var b strings.Builder
+
+	for index := range s.ciphertexts {
+		plaintext, _ := s.Plaintext(index)
+		fmt.Fprintf(&b, "%2d ", index)
+		for i, p := range plaintext {
+			switch {
+			case !s.known[i]:
+				b.WriteByte('_')
+			case p < 0x20 || p > 0x7e:
+				b.WriteByte('?')
+			default:
+				b.WriteByte(p)
+			}
+		}
+		b.WriteByte('\n')
+	}
+
+	return b.String()
+}
@@ -0,0 +1,47 @@
+package fixednoncectr
+
+import (
+	"crypto/aes"
+	"encoding/base64"
+	"encoding/binary"
+)
+
+// Encrypt encrypts plaintext with AES in CTR mode as specified by Cryptopals: the counter block is the
+// 64-bit nonce followed by the 64-bit block counter, both little-endian, and the counter starts at 0.
+// Decryption is the same operation.
+func Encrypt(plaintext, key []byte, nonce uint64) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	output := make([]byte, len(plaintext))
+	counterBlock := make([]byte, aes.BlockSize)
+	keystream := make([]byte, aes.BlockSize)
+	binary.LittleEndian.PutUint64(counterBlock[:8], nonce)
+
+	for i := 0; i < len(plaintext); i += aes.BlockSize {
+		binary.LittleEndian.PutUint64(counterBlock[8:], uint64(i/aes.BlockSize))
+		block.Encrypt(keystream, counterBlock)
+
+		for j := i; j < len(plaintext) && j < i+aes.BlockSize; j++ {
+			output[j] = plaintext[j] ^ keystream[j-i]
+		}
+	}
+
+	return output, nil
+}
+
+// Decrypt decrypts ciphertext encrypted by Encrypt with the same key and nonce.
+func Decrypt(ciphertext, key []byte, nonce uint64) ([]byte, error) {
+	return Encrypt(ciphertext, key, nonce)
+}
+
+// DecryptFromBase64 decrypts base64-encoded CTR ciphertext.
+func DecryptFromBase64(ciphertext string, key []byte, nonce uint64) ([]byte, error) {
+	ciphertextBytes, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	return Decrypt(ciphertextBytes, key, nonce)
+}
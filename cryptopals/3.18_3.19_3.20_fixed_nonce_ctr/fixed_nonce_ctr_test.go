@@ -0,0 +1,280 @@
+package fixednoncectr
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	ecb "cryptography-challenge/1.7_1.8_ecb"
+)
+
+// encryptLines encrypts every line under the same random key and nonce 0.
+func encryptLines(t *testing.T, lines [][]byte) [][]byte {
+	key := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("error generating key: %v", err)
+	}
+
+	ciphertexts := make([][]byte, len(lines))
+	for i, line := range lines {
+		c, err := Encrypt(line, key, 0)
+		if err != nil {
+			t.Fatalf("Encrypt() error = %v", err)
+		}
+		ciphertexts[i] = c
+	}
+	return ciphertexts
+}
+
+// readBase64Lines returns the plaintexts of a challenge file with one base64 line per plaintext.
+func readBase64Lines(t *testing.T, path string) [][]byte {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("error reading %s: %v", path, err)
+	}
+
+	var lines [][]byte
+	for _, line := range strings.Fields(string(data)) {
+		plaintext, err := base64.StdEncoding.DecodeString(line)
+		if err != nil {
+			t.Fatalf("error decoding %q: %v", line, err)
+		}
+		lines = append(lines, plaintext)
+	}
+	return lines
+}
+
+// correctBytes counts the bytes of got equal to want, ignoring case.
+func correctBytes(got, want []byte) int {
+	n := 0
+	for i := range got {
+		if bytes.EqualFold(got[i:i+1], want[i:i+1]) {
+			n++
+		}
+	}
+	return n
+}
+
+func Test_Decrypt(t *testing.T) {
+	type args struct {
+		ciphertext string
+		key        []byte
+		nonce      uint64
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []byte
+		wantErr bool
+	}{
+		{
+			name: "Test DecryptFromBase64 challenge 18",
+			args: args{
+				ciphertext: "L77na/nrFsKvynd6HzOoG7GHTLXsTVu9qvY/2syLXzhPweyyMTJULu/6/kXX0KSvoOLSFQ==",
+				key:        []byte("YELLOW SUBMARINE"),
+				nonce:      0,
+			},
+			want:    []byte("Yo, VIP Let's kick it Ice, Ice, baby Ice, Ice, baby "),
+			wantErr: false,
+		},
+		{
+			name: "Test DecryptFromBase64 with invalid key",
+			args: args{
+				ciphertext: "L77na/nrFsKvynd6HzOoG7GHTLXsTVu9qvY/2syLXzhPweyyMTJULu/6/kXX0KSvoOLSFQ==",
+				key:        []byte("short key"),
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "Test DecryptFromBase64 with invalid base64",
+			args: args{
+				ciphertext: "not base64!",
+				key:        []byte("YELLOW SUBMARINE"),
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecryptFromBase64(tt.args.ciphertext, tt.args.key, tt.args.nonce)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DecryptFromBase64() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DecryptFromBase64() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_EncryptCounterFormat(t *testing.T) {
+	key := []byte("YELLOW SUBMARINE")
+	nonce := uint64(0x0102030405060708)
+	plaintext := bytes.Repeat([]byte("counter format "), 5)
+
+	got, err := Encrypt(plaintext, key, nonce)
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+
+	// The standard library increments the whole block as a big-endian integer, which matches the
+	// little-endian counter as long as only the first counter byte changes
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatalf("aes.NewCipher() error = %v", err)
+	}
+	want := make([]byte, len(plaintext))
+	for i := 0; i < len(plaintext); i += aes.BlockSize {
+		counterBlock := make([]byte, aes.BlockSize)
+		binary.LittleEndian.PutUint64(counterBlock[:8], nonce)
+		binary.LittleEndian.PutUint64(counterBlock[8:], uint64(i/aes.BlockSize))
+		end := i + aes.BlockSize
+		if end > len(plaintext) {
+			end = len(plaintext)
+		}
+		cipher.NewCTR(block, counterBlock).XORKeyStream(want[i:end], plaintext[i:end])
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("Encrypt() got = %x, want %x", got, want)
+	}
+}
+
+func Test_CribSolver(t *testing.T) {
+	lines := readBase64Lines(t, "19.txt")
+	ciphertexts := encryptLines(t, lines)
+
+	s := NewCribSolver(ciphertexts)
+	if err := s.GuessFromStatistics(10); err != nil {
+		t.Fatalf("GuessFromStatistics() error = %v", err)
+	}
+
+	// Statistics alone get most of the first columns right, where many lines overlap
+	correct, total := 0, 0
+	for i, line := range lines {
+		plaintext, _ := s.Plaintext(i)
+		n := len(line)
+		if n > 20 {
+			n = 20
+		}
+		correct += correctBytes(plaintext[:n], line[:n])
+		total += n
+	}
+	if correct < total*8/10 {
+		t.Errorf("GuessFromStatistics() recovered %d of %d bytes, want at least 80%%", correct, total)
+	}
+
+	// A wrong crib can be undone
+	if err := s.Guess(0, 0, "xxxxxxxxxx"); err != nil {
+		t.Fatalf("Guess() error = %v", err)
+	}
+	s.Forget(0, 10)
+	if _, known := s.Keystream(); known[0] {
+		t.Errorf("Forget() left keystream byte 0 known")
+	}
+
+	// The longest line is the last one to be fully decrypted; guessing it reveals the whole keystream
+	longest := 0
+	for i, c := range ciphertexts {
+		if len(c) > len(ciphertexts[longest]) {
+			longest = i
+		}
+	}
+	if err := s.Guess(longest, 0, string(lines[longest])); err != nil {
+		t.Fatalf("Guess() error = %v", err)
+	}
+
+	for i, line := range lines {
+		got, complete := s.Plaintext(i)
+		if !complete || !bytes.Equal(got, line) {
+			t.Errorf("Plaintext(%d) got = %q (complete %v), want %q", i, got, complete, line)
+		}
+	}
+	if !strings.Contains(s.String(), "A terrible beauty is born.") {
+		t.Errorf("String() does not show the plaintexts:\n%s", s)
+	}
+
+	if err := s.Guess(0, 30, "crib too long for this line"); err == nil {
+		t.Errorf("Guess() past the end of a ciphertext error = nil, want error")
+	}
+	if err := s.Guess(len(lines), 0, "x"); err == nil {
+		t.Errorf("Guess() with an invalid index error = nil, want error")
+	}
+}
+
+// breakLines encrypts lines under one key and nonce, breaks them with BreakFixedNonce and returns the recovered
+// plaintexts, which are as long as the shortest line.
+func breakLines(t *testing.T, lines [][]byte) [][]byte {
+	shortest := len(lines[0])
+	for _, line := range lines {
+		if len(line) < shortest {
+			shortest = len(line)
+		}
+	}
+
+	keystream, plaintexts, err := BreakFixedNonce(encryptLines(t, lines))
+	if err != nil {
+		t.Fatalf("BreakFixedNonce() error = %v", err)
+	}
+	if len(keystream) != shortest {
+		t.Fatalf("BreakFixedNonce() keystream length = %d, want %d", len(keystream), shortest)
+	}
+	return plaintexts
+}
+
+func Test_BreakFixedNonce(t *testing.T) {
+	if _, err := os.Stat("20.txt"); os.IsNotExist(err) {
+		t.Skip("20.txt is not in the repository yet, download it from https://cryptopals.com/static/challenge-data/20.txt")
+	}
+	lines := readBase64Lines(t, "20.txt")
+
+	// Frequency analysis can pick the wrong case, or a wrong byte in a column where no candidate stands out
+	for i, got := range breakLines(t, lines) {
+		want := lines[i][:len(got)]
+		if wrong := len(got) - correctBytes(got, want); wrong > 3 {
+			t.Errorf("BreakFixedNonce() line %d got = %q with %d wrong bytes, want %q", i, got, wrong, want)
+		}
+	}
+}
+
+func Test_BreakFixedNonceWithChallenge7(t *testing.T) {
+	// The lines of the challenge 7 plaintext are fewer and shorter than those of 20.txt, so fewer bytes come out right
+	data, err := os.ReadFile("../1.7_1.8_ecb/1.7.txt")
+	if err != nil {
+		t.Fatalf("error reading 1.7.txt: %v", err)
+	}
+	plaintext, err := ecb.DecryptFromBase64(string(data), []byte("YELLOW SUBMARINE"))
+	if err != nil {
+		t.Fatalf("ecb.DecryptFromBase64() error = %v", err)
+	}
+
+	var lines [][]byte
+	for _, line := range bytes.Split(plaintext, []byte("\n")) {
+		if len(line) >= 30 {
+			lines = append(lines, line)
+		}
+	}
+
+	correct, total := 0, 0
+	for i, got := range breakLines(t, lines) {
+		correct += correctBytes(got, lines[i][:len(got)])
+		total += len(got)
+	}
+	if correct < total*9/10 {
+		t.Errorf("BreakFixedNonce() recovered %d of %d bytes, want at least 90%%", correct, total)
+	}
+
+	if _, _, err := BreakFixedNonce(nil); err == nil {
+		t.Errorf("BreakFixedNonce() with no ciphertext error = nil, want error")
+	}
+}
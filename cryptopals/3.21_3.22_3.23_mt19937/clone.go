@@ -0,0 +1,54 @@
+package mt19937
+
+import "fmt"
+
+// Untemper inverts the tempering of an output, and returns the word of state it was computed from.
+func Untemper(y uint32) uint32 {
+	y = undoRightShiftXor(y, 18)
+	y = undoLeftShiftXorAnd(y, 15, 0xefc60000)
+	y = undoLeftShiftXorAnd(y, 7, 0x9d2c5680)
+	y = undoRightShiftXor(y, 11)
+	return y
+}
+
+// undoRightShiftXor inverts y ^= y >> shift. The top shift bits of the result are already right, and every
+// iteration recovers shift more bits from them.
+func undoRightShiftXor(y uint32, shift uint) uint32 {
+	x := y
+	for i := shift; i < 32; i += shift {
+		x = y ^ x>>shift
+	}
+	return x
+}
+
+// undoLeftShiftXorAnd inverts y ^= y << shift & mask, recovering the bits from the bottom up.
+func undoLeftShiftXorAnd(y uint32, shift uint, mask uint32) uint32 {
+	x := y
+	for i := shift; i < 32; i += shift {
+		x = y ^ x<<shift&mask
+	}
+	return x
+}
+
+// Clone rebuilds a generator from StateSize consecutive outputs of another one, starting right after a twist,
+// as it is for the first outputs of a freshly seeded generator. The clone returns the same outputs as the original
+// from then on.
+func Clone(outputs []uint32) (*MT19937, error) {
+	if len(outputs) < StateSize {
+		return nil, fmt.Errorf("error cloning generator: need %d outputs, got %d", StateSize, len(outputs))
+	}
+
+	mt := &MT19937{index: n}
+	for i := range mt.state {
+		mt.state[i] = Untemper(outputs[i])
+	}
+
+	// Outputs after the first StateSize ones must be generated by the clone
+	for _, output := range outputs[StateSize:] {
+		if mt.Uint32() != output {
+			return nil, fmt.Errorf("error cloning generator: outputs are not consecutive outputs of MT19937")
+		}
+	}
+
+	return mt, nil
+}
@@ -0,0 +1,72 @@
+package mt19937
+
+// Parameters of the 32-bit Mersenne Twister, MT19937.
+const (
+	n         = 624
+	m         = 397
+	matrixA   = 0x9908b0df
+	upperMask = 0x80000000
+	lowerMask = 0x7fffffff
+	f         = 1812433253
+
+	// StateSize is the number of 32-bit words of state, and the number of outputs needed to clone a generator.
+	StateSize = n
+)
+
+// MT19937 is the 32-bit Mersenne Twister. It is fast and has good statistical properties, but it is NOT a
+// cryptographically secure generator: its state can be rebuilt from 624 consecutive outputs.
+type MT19937 struct {
+	state [n]uint32
+	index int
+}
+
+// New returns a generator initialized with seed, like init_genrand in the reference implementation.
+func New(seed uint32) *MT19937 {
+	mt := &MT19937{}
+	mt.Seed(seed)
+	return mt
+}
+
+// Seed reinitializes the generator with seed.
+func (mt *MT19937) Seed(seed uint32) {
+	mt.state[0] = seed
+	for i := 1; i < n; i++ {
+		mt.state[i] = f*(mt.state[i-1]^(mt.state[i-1]>>30)) + uint32(i)
+	}
+	// The state is twisted before the first output
+	mt.index = n
+}
+
+// Uint32 returns the next 32-bit output, like genrand_int32 in the reference implementation.
+func (mt *MT19937) Uint32() uint32 {
+	if mt.index >= n {
+		mt.twist()
+	}
+
+	y := mt.state[mt.index]
+	mt.index++
+
+	return temper(y)
+}
+
+// twist generates the next n words of state.
+func (mt *MT19937) twist() {
+	for i := 0; i < n; i++ {
+		y := mt.state[i]&upperMask | mt.state[(i+1)%n]&lowerMask
+		next := mt.state[(i+m)%n] ^ y>>1
+		if y&1 != 0 {
+			next ^= matrixA
+		}
+		mt.state[i] = next
+	}
+	mt.index = 0
+}
+
+// temper scrambles a word of state into an output. It is invertible, see Untemper.
+func temper(y uint32) uint32 {
+	y ^= y >> 11
+	y ^= y << 7 & 0x9d2c5680
+	y ^= y << 15 & 0xefc60000
+	y ^= y >> 18
+	return y
+}
@@ -0,0 +1,141 @@
+package mt19937
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeClock moves forward when it sleeps, instead of waiting.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func Test_Uint32(t *testing.T) {
+	tests := []struct {
+		name string
+		seed uint32
+		want []uint32
+	}{
+		{
+			// Default seed of the reference implementation, and of std::mt19937
+			name: "Test Uint32 with seed 5489",
+			seed: 5489,
+			want: []uint32{3499211612, 581869302, 3890346734, 3586334585, 545404204},
+		},
+		{
+			name: "Test Uint32 with seed 0",
+			seed: 0,
+			want: []uint32{2357136044, 2546248239, 3071714933, 3626093760, 2588848963},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mt := New(tt.seed)
+			got := make([]uint32, len(tt.want))
+			for i := range got {
+				got[i] = mt.Uint32()
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Uint32() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Uint32TenThousandth(t *testing.T) {
+	// The 10000th output of std::mt19937 with the default seed is required by the C++ standard
+	mt := New(5489)
+	for i := 1; i < 10000; i++ {
+		mt.Uint32()
+	}
+	if got := mt.Uint32(); got != 4123659995 {
+		t.Errorf("Uint32() 10000th output got = %d, want 4123659995", got)
+	}
+}
+
+func Test_Untemper(t *testing.T) {
+	tests := []struct {
+		name string
+		y    uint32
+	}{
+		{name: "Test Untemper 0", y: 0},
+		{name: "Test Untemper all ones", y: 0xffffffff},
+		{name: "Test Untemper alternating bits", y: 0xaaaaaaaa},
+		{name: "Test Untemper 0x12345678", y: 0x12345678},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Untemper(temper(tt.y)); got != tt.y {
+				t.Errorf("Untemper() got = %#x, want %#x", got, tt.y)
+			}
+		})
+	}
+}
+
+func Test_Clone(t *testing.T) {
+	original := New(uint32(time.Now().UnixNano()))
+	outputs := make([]uint32, StateSize)
+	for i := range outputs {
+		outputs[i] = original.Uint32()
+	}
+
+	clone, err := Clone(outputs)
+	if err != nil {
+		t.Fatalf("Clone() error = %v", err)
+	}
+
+	// The clone predicts every output of the original, across several twists
+	for i := 0; i < 3*StateSize; i++ {
+		want := original.Uint32()
+		if got := clone.Uint32(); got != want {
+			t.Fatalf("Uint32() of the clone at output %d got = %d, want %d", i, got, want)
+		}
+	}
+
+	if _, err := Clone(outputs[:StateSize-1]); err == nil {
+		t.Errorf("Clone() with too few outputs error = nil, want error")
+	}
+
+	// More outputs than the state are checked against the clone
+	more := append(append([]uint32{}, outputs...), New(1).Uint32())
+	if _, err := Clone(more); err == nil {
+		t.Errorf("Clone() with an output from another generator error = nil, want error")
+	}
+}
+
+func Test_CrackTimestampSeed(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+
+	for i := 0; i < 5; i++ {
+		output, seed, err := TimestampSeededOutput(clock)
+		if err != nil {
+			t.Fatalf("TimestampSeededOutput() error = %v", err)
+		}
+
+		got, err := CrackTimestampSeed(output, clock, 2000*time.Second)
+		if err != nil {
+			t.Fatalf("CrackTimestampSeed() error = %v", err)
+		}
+		if got != seed {
+			t.Errorf("CrackTimestampSeed() got = %d, want %d", got, seed)
+		}
+	}
+
+	// A seed older than maxAge is not found
+	output, _, err := TimestampSeededOutput(clock)
+	if err != nil {
+		t.Fatalf("TimestampSeededOutput() error = %v", err)
+	}
+	if _, err := CrackTimestampSeed(output, clock, 30*time.Second); err == nil {
+		t.Errorf("CrackTimestampSeed() with a too short maxAge error = nil, want error")
+	}
+}
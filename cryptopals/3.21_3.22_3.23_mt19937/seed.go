@@ -0,0 +1,66 @@
+package mt19937
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// Clock tells the time and waits. Tests use a fake clock, so they do not have to wait for minutes.
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+}
+
+// SystemClock is the real clock.
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (SystemClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+// TimestampSeededOutput waits 40 to 1000 seconds, seeds a generator with the current Unix timestamp, waits 40 to 1000
+// seconds again and returns the first output of the generator. It also returns the seed, so a cracker can be checked.
+func TimestampSeededOutput(clock Clock) (output uint32, seed uint32, err error) {
+	if err := sleepRandom(clock, 40, 1000); err != nil {
+		return 0, 0, err
+	}
+
+	seed = uint32(clock.Now().Unix())
+	output = New(seed).Uint32()
+
+	if err := sleepRandom(clock, 40, 1000); err != nil {
+		return 0, 0, err
+	}
+
+	return output, seed, nil
+}
+
+// CrackTimestampSeed finds the seed of a generator whose first output is output, and which was seeded with the Unix
+// timestamp at most maxAge ago. It tries every second from now back to now - maxAge.
+func CrackTimestampSeed(output uint32, clock Clock, maxAge time.Duration) (uint32, error) {
+	now := clock.Now().Unix()
+	for t := now; t >= now-int64(maxAge/time.Second); t-- {
+		if New(uint32(t)).Uint32() == output {
+			return uint32(t), nil
+		}
+	}
+
+	return 0, fmt.Errorf("error cracking seed: no timestamp in the last %v gives output %d", maxAge, output)
+}
+
+// sleepRandom sleeps for a random whole number of seconds in [min, max].
+func sleepRandom(clock Clock, min, max int64) error {
+	n, err := rand.Int(rand.Reader, big.NewInt(max-min+1))
+	if err != nil {
+		return fmt.Errorf("error generating random delay: %v", err)
+	}
+
+	clock.Sleep(time.Duration(min+n.Int64()) * time.Second)
+	return nil
+}
@@ -0,0 +1,137 @@
+package mtstream
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+	"time"
+)
+
+// fakeClock moves forward when it sleeps, instead of waiting.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func Test_XORKeyStream(t *testing.T) {
+	block, err := aes.NewCipher([]byte("YELLOW SUBMARINE"))
+	if err != nil {
+		t.Fatalf("aes.NewCipher() error = %v", err)
+	}
+	iv := make([]byte, aes.BlockSize)
+
+	// The MT19937 stream cipher is used exactly like the CTR and OFB modes of the ctr and ofb demos
+	tests := []struct {
+		name      string
+		newStream func() cipher.Stream
+	}{
+		{name: "Test XORKeyStream MT19937", newStream: func() cipher.Stream { return NewCipher(0x1234) }},
+		{name: "Test XORKeyStream CTR", newStream: func() cipher.Stream { return cipher.NewCTR(block, iv) }},
+		{name: "Test XORKeyStream OFB", newStream: func() cipher.Stream { return cipher.NewOFB(block, iv) }},
+	}
+	plaintext := []byte("Hello World! The same plaintext goes through every stream cipher.")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ciphertext := make([]byte, len(plaintext))
+			tt.newStream().XORKeyStream(ciphertext, plaintext)
+			if bytes.Equal(ciphertext, plaintext) {
+				t.Fatalf("XORKeyStream() did not change the plaintext")
+			}
+
+			// Encrypting in uneven chunks gives the same ciphertext as encrypting at once
+			chunked := make([]byte, len(plaintext))
+			stream := tt.newStream()
+			for i, size := 0, 1; i < len(plaintext); i, size = i+size, size+2 {
+				end := i + size
+				if end > len(plaintext) {
+					end = len(plaintext)
+				}
+				stream.XORKeyStream(chunked[i:end], plaintext[i:end])
+			}
+			if !bytes.Equal(chunked, ciphertext) {
+				t.Errorf("XORKeyStream() in chunks got = %x, want %x", chunked, ciphertext)
+			}
+
+			decrypted := make([]byte, len(ciphertext))
+			tt.newStream().XORKeyStream(decrypted, ciphertext)
+			if !bytes.Equal(decrypted, plaintext) {
+				t.Errorf("XORKeyStream() decrypted got = %q, want %q", decrypted, plaintext)
+			}
+		})
+	}
+}
+
+func Test_RecoverSeed(t *testing.T) {
+	oracle, err := NewOracle()
+	if err != nil {
+		t.Fatalf("NewOracle() error = %v", err)
+	}
+
+	known := bytes.Repeat([]byte{'A'}, 14)
+	ciphertext, err := oracle.Encrypt(known)
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+
+	seed, err := RecoverSeed(ciphertext, known)
+	if err != nil {
+		t.Fatalf("RecoverSeed() error = %v", err)
+	}
+	if seed != oracle.seed {
+		t.Errorf("RecoverSeed() got = %d, want %d", seed, oracle.seed)
+	}
+	if got := Decrypt(ciphertext, seed); !bytes.HasSuffix(got, known) {
+		t.Errorf("Decrypt() got = %q, want suffix %q", got, known)
+	}
+
+	if _, err := RecoverSeed(ciphertext[:3], known); err == nil {
+		t.Errorf("RecoverSeed() with a suffix longer than the ciphertext error = nil, want error")
+	}
+}
+
+func Test_IsTimeSeededToken(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	token := PasswordResetToken(clock)
+	clock.Sleep(10 * time.Minute)
+
+	random := make([]byte, TokenSize)
+	if _, err := rand.Read(random); err != nil {
+		t.Fatalf("error generating random token: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		maxAge  time.Duration
+		want    bool
+		wantErr bool
+	}{
+		{name: "Test IsTimeSeededToken with a time-seeded token", token: token, maxAge: time.Hour, want: true},
+		{name: "Test IsTimeSeededToken with a token older than maxAge", token: token, maxAge: time.Minute, want: false},
+		{name: "Test IsTimeSeededToken with a random token", token: hex.EncodeToString(random), maxAge: time.Hour, want: false},
+		{name: "Test IsTimeSeededToken with a short token", token: token[:8], maxAge: time.Hour, want: false},
+		{name: "Test IsTimeSeededToken with an invalid token", token: "not hex", maxAge: time.Hour, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := IsTimeSeededToken(tt.token, clock, tt.maxAge)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("IsTimeSeededToken() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("IsTimeSeededToken() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
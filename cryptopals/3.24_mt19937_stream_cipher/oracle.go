@@ -0,0 +1,82 @@
+package mtstream
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"time"
+
+	mt19937 "cryptography-challenge/3.21_3.22_3.23_mt19937"
+)
+
+// TokenSize is the number of random bytes in a password reset token.
+const TokenSize = 16
+
+// Oracle encrypts its input under a random seed, after a random prefix of 5 to 20 random bytes.
+type Oracle struct {
+	seed uint16
+}
+
+// NewOracle returns an oracle with a random seed.
+func NewOracle() (*Oracle, error) {
+	var seed [2]byte
+	if _, err := rand.Read(seed[:]); err != nil {
+		return nil, fmt.Errorf("error generating seed: %v", err)
+	}
+
+	return &Oracle{seed: binary.BigEndian.Uint16(seed[:])}, nil
+}
+
+// Encrypt encrypts random prefix || plaintext.
+func (o *Oracle) Encrypt(plaintext []byte) ([]byte, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(16))
+	if err != nil {
+		return nil, fmt.Errorf("error generating prefix length: %v", err)
+	}
+
+	prefix := make([]byte, 5+n.Int64())
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, fmt.Errorf("error generating prefix: %v", err)
+	}
+
+	return Encrypt(append(prefix, plaintext...), o.seed), nil
+}
+
+// PasswordResetToken returns a hex-encoded token made of the first TokenSize bytes of keystream of MT19937 seeded
+// with the current Unix timestamp, the way a careless server would.
+func PasswordResetToken(clock mt19937.Clock) string {
+	return hex.EncodeToString(timeSeededKeystream(uint32(clock.Now().Unix())))
+}
+
+// IsTimeSeededToken tells whether token came from PasswordResetToken in the last maxAge, by trying every timestamp
+// from now back to now - maxAge.
+func IsTimeSeededToken(token string, clock mt19937.Clock, maxAge time.Duration) (bool, error) {
+	raw, err := hex.DecodeString(token)
+	if err != nil {
+		return false, fmt.Errorf("error decoding token: %v", err)
+	}
+	if len(raw) != TokenSize {
+		return false, nil
+	}
+
+	now := clock.Now().Unix()
+	for t := now; t >= now-int64(maxAge/time.Second); t-- {
+		if string(timeSeededKeystream(uint32(t))) == string(raw) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// timeSeededKeystream returns TokenSize bytes of keystream of MT19937 seeded with seed, the full 32-bit timestamp.
+func timeSeededKeystream(seed uint32) []byte {
+	mt := mt19937.New(seed)
+	keystream := make([]byte, TokenSize)
+	for i := 0; i < TokenSize; i += 4 {
+		binary.BigEndian.PutUint32(keystream[i:], mt.Uint32())
+	}
+	return keystream
+}
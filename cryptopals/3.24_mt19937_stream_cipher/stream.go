@@ -0,0 +1,69 @@
+package mtstream
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"fmt"
+
+	mt19937 "cryptography-challenge/3.21_3.22_3.23_mt19937"
+)
+
+// stream is a stream cipher whose keystream is the output of MT19937, seeded with the key. Each output gives
+// 4 bytes of keystream, in big-endian order.
+type stream struct {
+	mt  *mt19937.MT19937
+	buf [4]byte
+	// pos is the number of bytes of buf already used
+	pos int
+}
+
+// NewCipher returns the MT19937 stream cipher keyed with a 16-bit seed. It is a cipher.Stream, like the CTR and OFB
+// modes, but a 16-bit key can be found by trying all of them, see RecoverSeed.
+func NewCipher(seed uint16) cipher.Stream {
+	return &stream{mt: mt19937.New(uint32(seed)), pos: len(stream{}.buf)}
+}
+
+func (s *stream) XORKeyStream(dst, src []byte) {
+	if len(dst) < len(src) {
+		panic("mtstream: output smaller than input")
+	}
+
+	for i := range src {
+		if s.pos == len(s.buf) {
+			binary.BigEndian.PutUint32(s.buf[:], s.mt.Uint32())
+			s.pos = 0
+		}
+		dst[i] = src[i] ^ s.buf[s.pos]
+		s.pos++
+	}
+}
+
+// Encrypt encrypts plaintext with the MT19937 stream cipher keyed with seed.
+func Encrypt(plaintext []byte, seed uint16) []byte {
+	ciphertext := make([]byte, len(plaintext))
+	NewCipher(seed).XORKeyStream(ciphertext, plaintext)
+	return ciphertext
+}
+
+// Decrypt decrypts ciphertext with the MT19937 stream cipher keyed with seed.
+func Decrypt(ciphertext []byte, seed uint16) []byte {
+	return Encrypt(ciphertext, seed)
+}
+
+// RecoverSeed finds the seed of a ciphertext whose plaintext ends with knownSuffix, by trying all 65536 seeds.
+func RecoverSeed(ciphertext, knownSuffix []byte) (uint16, error) {
+	if len(knownSuffix) == 0 || len(knownSuffix) > len(ciphertext) {
+		return 0, fmt.Errorf("error recovering seed: known suffix must be 1 to %d bytes, got %d", len(ciphertext), len(knownSuffix))
+	}
+
+	offset := len(ciphertext) - len(knownSuffix)
+	plaintext := make([]byte, len(ciphertext))
+	for seed := 0; seed <= 0xffff; seed++ {
+		NewCipher(uint16(seed)).XORKeyStream(plaintext, ciphertext)
+		if string(plaintext[offset:]) == string(knownSuffix) {
+			return uint16(seed), nil
+		}
+	}
+
+	return 0, fmt.Errorf("error recovering seed: no 16-bit seed decrypts to the known suffix")
+}
@@ -0,0 +1,65 @@
+package ctredit
+
+import (
+	"crypto/aes"
+	"crypto/subtle"
+	"fmt"
+
+	"github.com/japananh/crypto/modes"
+)
+
+// EditOracle is the edit API exposed to an attacker: it edits a ciphertext under a key the attacker does not know.
+type EditOracle interface {
+	Edit(ciphertext []byte, offset int, newtext []byte) ([]byte, error)
+}
+
+// Oracle encrypts with CTR mode under a random key, and lets anyone edit its ciphertexts.
+type Oracle struct {
+	key []byte
+}
+
+// NewOracle returns an oracle with a random AES-128 key.
+func NewOracle() (*Oracle, error) {
+	key, err := modes.GenerateAESKey(aes.BlockSize)
+	if err != nil {
+		return nil, fmt.Errorf("error generating key: %v", err)
+	}
+
+	return &Oracle{key: key}, nil
+}
+
+// Encrypt encrypts plaintext with CTR mode under a random initial counter block, and returns it || ciphertext.
+func (o *Oracle) Encrypt(plaintext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(o.key)
+	if err != nil {
+		return nil, fmt.Errorf("error creating AES cipher: %v", err)
+	}
+
+	return modes.NewCTR(block).Encrypt(plaintext)
+}
+
+// Edit replaces the plaintext at offset with newtext, see Edit.
+func (o *Oracle) Edit(ciphertext []byte, offset int, newtext []byte) ([]byte, error) {
+	return Edit(ciphertext, o.key, offset, newtext)
+}
+
+// RecoverPlaintext decrypts ciphertext with nothing but the edit oracle. Editing the whole plaintext to zeros
+// returns the keystream itself, because CTR reuses the keystream of the position it writes to; XORing it with
+// the original ciphertext gives the plaintext.
+func RecoverPlaintext(o EditOracle, ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < aes.BlockSize {
+		return nil, fmt.Errorf("error recovering plaintext: ciphertext is shorter than the counter block")
+	}
+	data := ciphertext[aes.BlockSize:]
+
+	edited, err := o.Edit(ciphertext, 0, make([]byte, len(data)))
+	if err != nil {
+		return nil, err
+	}
+	keystream := edited[aes.BlockSize:]
+
+	plaintext := make([]byte, len(data))
+	subtle.XORBytes(plaintext, data, keystream)
+
+	return plaintext, nil
+}
@@ -0,0 +1,126 @@
+package ctredit
+
+import (
+	"bytes"
+	"crypto/aes"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/japananh/crypto/modes"
+
+	ecb "cryptography-challenge/1.7_1.8_ecb"
+)
+
+func Test_Edit(t *testing.T) {
+	key := []byte("YELLOW SUBMARINE")
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatalf("aes.NewCipher() error = %v", err)
+	}
+	plaintext := []byte("The quick brown fox jumps over the lazy dog")
+	ciphertext, err := modes.NewCTR(block).Encrypt(plaintext)
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+
+	type args struct {
+		offset  int
+		newtext []byte
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []byte
+		wantErr bool
+	}{
+		{
+			name:    "Test Edit at the start",
+			args:    args{offset: 0, newtext: []byte("A")},
+			want:    []byte("Ahe quick brown fox jumps over the lazy dog"),
+			wantErr: false,
+		},
+		{
+			name:    "Test Edit across a block boundary",
+			args:    args{offset: 10, newtext: []byte("red cat slides!")},
+			want:    []byte("The quick red cat slides! over the lazy dog"),
+			wantErr: false,
+		},
+		{
+			name:    "Test Edit past the end grows the plaintext",
+			args:    args{offset: 40, newtext: []byte("dog and cat")},
+			want:    []byte("The quick brown fox jumps over the lazy dog and cat"),
+			wantErr: false,
+		},
+		{
+			name:    "Test Edit with a negative offset",
+			args:    args{offset: -1, newtext: []byte("A")},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "Test Edit with an offset after the end",
+			args:    args{offset: len(plaintext) + 1, newtext: []byte("A")},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := append([]byte{}, ciphertext...)
+
+			edited, err := Edit(ciphertext, key, tt.args.offset, tt.args.newtext)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Edit() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !bytes.Equal(ciphertext, original) {
+				t.Fatalf("Edit() modified the ciphertext")
+			}
+			if tt.wantErr {
+				return
+			}
+
+			got, err := modes.NewCTR(block).Decrypt(edited)
+			if err != nil {
+				t.Fatalf("Decrypt() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Edit() decrypted got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_RecoverPlaintext(t *testing.T) {
+	// The plaintext of challenge 25 is the plaintext of challenge 7
+	data, err := os.ReadFile("../1.7_1.8_ecb/1.7.txt")
+	if err != nil {
+		t.Fatalf("error reading 1.7.txt: %v", err)
+	}
+	plaintext, err := ecb.DecryptFromBase64(string(data), []byte("YELLOW SUBMARINE"))
+	if err != nil {
+		t.Fatalf("ecb.DecryptFromBase64() error = %v", err)
+	}
+
+	oracle, err := NewOracle()
+	if err != nil {
+		t.Fatalf("NewOracle() error = %v", err)
+	}
+	ciphertext, err := oracle.Encrypt(plaintext)
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+
+	got, err := RecoverPlaintext(oracle, ciphertext)
+	if err != nil {
+		t.Fatalf("RecoverPlaintext() error = %v", err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Errorf("RecoverPlaintext() got = %q, want %q", got, plaintext)
+	}
+
+	if _, err := RecoverPlaintext(oracle, ciphertext[:aes.BlockSize-1]); err == nil {
+		t.Errorf("RecoverPlaintext() with a truncated ciphertext error = nil, want error")
+	}
+}
@@ -0,0 +1,81 @@
+package ctredit
+
+import (
+	"crypto/aes"
+	"fmt"
+	"io"
+
+	"github.com/japananh/crypto/modes"
+)
+
+// Edit decrypts ciphertext, framed like the ciphertexts of modes.NewCTR (initial counter block || data),
+// replaces the plaintext at offset with newtext and returns the re-encrypted ciphertext. ciphertext is not modified.
+//
+// It seeks in the keystream with modes.CTRFile, the same code that serves random-access writes to encrypted files,
+// so it only encrypts the blocks it touches.
+func Edit(ciphertext, key []byte, offset int, newtext []byte) ([]byte, error) {
+	if offset < 0 || offset > len(ciphertext)-aes.BlockSize {
+		return nil, fmt.Errorf("error editing ciphertext: offset %d is outside the plaintext", offset)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("error creating AES cipher: %v", err)
+	}
+
+	f := &memFile{data: append([]byte{}, ciphertext...)}
+	ctrFile, err := modes.OpenCTRFile(block, f)
+	if err != nil {
+		return nil, fmt.Errorf("error opening ciphertext: %v", err)
+	}
+	if _, err := ctrFile.WriteAt(newtext, int64(offset)); err != nil {
+		return nil, fmt.Errorf("error editing ciphertext: %v", err)
+	}
+
+	return f.data, nil
+}
+
+// memFile is an in-memory modes.File.
+type memFile struct {
+	data   []byte
+	offset int64
+}
+
+func (f *memFile) ReadAt(p []byte, off int64) (int, error) {
+	if off >= int64(len(f.data)) {
+		return 0, io.EOF
+	}
+
+	n := copy(p, f.data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (f *memFile) WriteAt(p []byte, off int64) (int, error) {
+	if end := int(off) + len(p); end > len(f.data) {
+		f.data = append(f.data, make([]byte, end-len(f.data))...)
+	}
+
+	return copy(f.data[off:], p), nil
+}
+
+func (f *memFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += int64(len(f.data))
+	default:
+		return 0, fmt.Errorf("error seeking: invalid whence %d", whence)
+	}
+
+	if offset < 0 {
+		return 0, fmt.Errorf("error seeking: negative offset %d", offset)
+	}
+	f.offset = offset
+
+	return offset, nil
+}
@@ -1,3 +1,7 @@
 module cryptography-challenge
 
 go 1.20
+
+require github.com/japananh/crypto v0.0.0
+
+replace github.com/japananh/crypto => ../
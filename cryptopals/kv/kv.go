@@ -0,0 +1,71 @@
+// Package kv parses and encodes structured cookies such as "foo=bar&baz=qux&zap=zazzle",
+// used by the cut-and-paste and bit-flipping challenges.
+package kv
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Pair is a single key=value field.
+type Pair struct {
+	Key   string
+	Value string
+}
+
+// Values is an ordered list of fields. A key may appear more than once.
+type Values []Pair
+
+// Get returns the value of the first field with the given key.
+func (v Values) Get(key string) (string, bool) {
+	for _, p := range v {
+		if p.Key == key {
+			return p.Value, true
+		}
+	}
+	return "", false
+}
+
+// Parse parses fields separated by sep, such as "foo=bar&baz=qux" with sep '&'. Every field must contain '=',
+// and a value may itself contain '=': only the first one separates the key from the value. Empty fields are skipped.
+func Parse(s string, sep byte) (Values, error) {
+	var values Values
+
+	for _, field := range strings.Split(s, string(sep)) {
+		if field == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid field %q: missing '='", field)
+		}
+		if key == "" {
+			return nil, fmt.Errorf("invalid field %q: empty key", field)
+		}
+
+		values = append(values, Pair{Key: key, Value: value})
+	}
+
+	return values, nil
+}
+
+// Encode encodes fields in order, separated by sep. Keys and values are sanitized first,
+// so no input can add fields of its own.
+func Encode(values Values, sep byte) string {
+	fields := make([]string, len(values))
+	for i, p := range values {
+		fields[i] = Sanitize(p.Key, sep) + "=" + Sanitize(p.Value, sep)
+	}
+	return strings.Join(fields, string(sep))
+}
+
+// Sanitize removes the metacharacters of the encoding: the field separator sep and '='.
+func Sanitize(s string, sep byte) string {
+	return strings.Map(func(r rune) rune {
+		if r == '=' || r == rune(sep) {
+			return -1
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,128 @@
+package kv
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_Parse(t *testing.T) {
+	type args struct {
+		s   string
+		sep byte
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    Values
+		wantErr bool
+	}{
+		{
+			name: "Test Parse run successfully",
+			args: args{s: "foo=bar&baz=qux&zap=zazzle", sep: '&'},
+			want: Values{{Key: "foo", Value: "bar"}, {Key: "baz", Value: "qux"}, {Key: "zap", Value: "zazzle"}},
+		},
+		{
+			name: "Test Parse with another separator",
+			args: args{s: "comment1=cooking%20MCs;userdata=foo;admin=true", sep: ';'},
+			want: Values{{Key: "comment1", Value: "cooking%20MCs"}, {Key: "userdata", Value: "foo"}, {Key: "admin", Value: "true"}},
+		},
+		{
+			name: "Test Parse with an empty value and '=' in a value",
+			args: args{s: "a=&b=c=d", sep: '&'},
+			want: Values{{Key: "a", Value: ""}, {Key: "b", Value: "c=d"}},
+		},
+		{
+			name: "Test Parse skips empty fields",
+			args: args{s: "&a=1&&b=2&", sep: '&'},
+			want: Values{{Key: "a", Value: "1"}, {Key: "b", Value: "2"}},
+		},
+		{
+			name: "Test Parse empty string",
+			args: args{s: "", sep: '&'},
+			want: nil,
+		},
+		{
+			name:    "Test Parse field without '='",
+			args:    args{s: "a=1&garbage", sep: '&'},
+			wantErr: true,
+		},
+		{
+			name:    "Test Parse empty key",
+			args:    args{s: "=1", sep: '&'},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.args.s, tt.args.sep)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Encode(t *testing.T) {
+	tests := []struct {
+		name   string
+		values Values
+		sep    byte
+		want   string
+	}{
+		{
+			name:   "Test Encode run successfully",
+			values: Values{{Key: "email", Value: "foo@bar.com"}, {Key: "uid", Value: "10"}, {Key: "role", Value: "user"}},
+			sep:    '&',
+			want:   "email=foo@bar.com&uid=10&role=user",
+		},
+		{
+			name:   "Test Encode removes metacharacters",
+			values: Values{{Key: "email", Value: "foo@bar.com&role=admin"}},
+			sep:    '&',
+			want:   "email=foo@bar.comroleadmin",
+		},
+		{
+			name:   "Test Encode only removes the given separator",
+			values: Values{{Key: "userdata", Value: ";admin=true&x"}},
+			sep:    ';',
+			want:   "userdata=admintrue&x",
+		},
+		{
+			name:   "Test Encode empty values",
+			values: nil,
+			sep:    '&',
+			want:   "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Encode(tt.values, tt.sep); got != tt.want {
+				t.Errorf("Encode() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_EncodeParse(t *testing.T) {
+	values := Values{{Key: "email", Value: "foo@bar.com"}, {Key: "uid", Value: "10"}, {Key: "role", Value: "user"}}
+
+	got, err := Parse(Encode(values, '&'), '&')
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, values) {
+		t.Errorf("Parse(Encode()) got = %v, want %v", got, values)
+	}
+
+	role, ok := got.Get("role")
+	if !ok || role != "user" {
+		t.Errorf("Get() got = %v, %v, want user, true", role, ok)
+	}
+	if _, ok := got.Get("admin"); ok {
+		t.Errorf("Get() found a missing key")
+	}
+}
@@ -5,9 +5,12 @@ import (
 	"bytes"
 	"crypto/aes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"os"
+
+	"github.com/japananh/crypto/padding"
 )
 
 // defaultEnglishLetterFrequencies creats a constant map contains the default English letter frequencies
@@ -140,30 +143,48 @@ func GetEnglishLetterFrequency(dirPath string, filePath string) (map[byte]float6
 }
 
 // Pkcs7Pad adds padding to the end of message to make its length a multiple of 16 (AES block size).
+// The padding is appended to a copy, so spare capacity of input is never written to.
 func Pkcs7Pad(input []byte) []byte {
 	paddingSize := aes.BlockSize - len(input)%aes.BlockSize
 	padding := bytes.Repeat([]byte{byte(paddingSize)}, paddingSize)
-	return append(input, padding...)
+	return append(input[:len(input):len(input)], padding...)
 }
 
-// Pkcs7Unpad removes padding from the decrypted data to obtain the original plaintext.
+// Pkcs7Unpad removes padding from the decrypted data to obtain the original plaintext. It uses padding.PKCS7,
+// which checks the padding in constant time, so the time it takes does not reveal which byte was wrong.
+// An empty input has no padding to remove, like the empty ciphertext that ecb.Encrypt returns for an empty plaintext.
 // References: https://en.wikipedia.org/wiki/PKCS_7
 func Pkcs7Unpad(input []byte) ([]byte, error) {
-	msgLength := len(input)
-	if msgLength == 0 {
+	if len(input) == 0 {
 		return input, nil
 	}
 
-	paddingSize := int(input[msgLength-1])
-	if paddingSize > msgLength || paddingSize == 0 {
-		return nil, fmt.Errorf("invalid padding")
+	unpadded, err := padding.PKCS7.Unpad(input, aes.BlockSize)
+	if errors.Is(err, padding.ErrInvalidPadding) {
+		return nil, &PaddingError{}
 	}
+	return unpadded, err
+}
 
-	for i := msgLength - 1; i <= msgLength-paddingSize; i++ {
-		if input[i] != byte(paddingSize) {
-			return nil, fmt.Errorf("invalid padding")
-		}
+// PaddingError is returned for invalid PKCS#7 padding. It deliberately does not say what is wrong with the padding:
+// a detailed error is a padding oracle.
+type PaddingError struct {
+	// Reason is set for errors that only depend on the length of the input
+	Reason string
+}
+
+func (e *PaddingError) Error() string {
+	if e.Reason != "" {
+		return "invalid PKCS#7 padding: " + e.Reason
 	}
+	return "invalid PKCS#7 padding"
+}
 
-	return input[:msgLength-paddingSize], nil
+// Pkcs7Validate checks that input is a whole number of blocks with valid PKCS#7 padding, and strips the padding.
+// Every failure is a *PaddingError.
+func Pkcs7Validate(input []byte) ([]byte, error) {
+	if len(input) == 0 || len(input)%aes.BlockSize != 0 {
+		return nil, &PaddingError{Reason: fmt.Sprintf("length %d is not a positive multiple of %d", len(input), aes.BlockSize)}
+	}
+	return Pkcs7Unpad(input)
 }
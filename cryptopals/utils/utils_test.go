@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"bytes"
+	"errors"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -265,6 +267,17 @@ func Test_Pkcs7Pad(t *testing.T) {
 			}
 		})
 	}
+
+	t.Run("Test pkcs7Pad does not write to the spare capacity of input", func(t *testing.T) {
+		buf := []byte("Hello world!XXXX")
+		got := Pkcs7Pad(buf[:12])
+		if string(buf) != "Hello world!XXXX" {
+			t.Errorf("pkcs7Pad() modified the input: %q", buf)
+		}
+		if want := append([]byte("Hello world!"), 4, 4, 4, 4); !reflect.DeepEqual(got, want) {
+			t.Errorf("pkcs7Pad() = %v, want %v", got, want)
+		}
+	})
 }
 
 func Test_Pkcs7Unpad(t *testing.T) {
@@ -285,6 +298,54 @@ func Test_Pkcs7Unpad(t *testing.T) {
 			want:    []byte{72, 101, 108, 108, 111, 32, 119, 111, 114, 108, 100, 33},
 			wantErr: false,
 		},
+		{
+			name: "Should throw error on inconsistent padding bytes",
+			args: args{
+				input: []byte{72, 101, 108, 108, 111, 32, 119, 111, 114, 108, 100, 33, 4, 3, 4, 4},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "Should throw error on wrong first padding byte",
+			args: args{
+				input: []byte{72, 101, 108, 108, 111, 32, 119, 111, 114, 108, 100, 33, 1, 4, 4, 4},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "Should throw error on zero padding size",
+			args: args{
+				input: []byte{72, 101, 108, 108, 111, 32, 119, 111, 114, 108, 100, 33, 0, 0, 0, 0},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "Test pkcs7Unpad with empty input",
+			args: args{
+				input: []byte{},
+			},
+			want:    []byte{},
+			wantErr: false,
+		},
+		{
+			name: "Should throw error on input that is not a whole number of blocks",
+			args: args{
+				input: []byte{72, 101, 108, 108, 111, 1},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "Should throw error on padding size larger than the block size",
+			args: args{
+				input: append(make([]byte, 15), 17, 17, 17, 17, 17, 17, 17, 17, 17, 17, 17, 17, 17, 17, 17, 17, 17),
+			},
+			want:    nil,
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -293,9 +354,78 @@ func Test_Pkcs7Unpad(t *testing.T) {
 				t.Errorf("pkcs7Unpad() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			var paddingErr *PaddingError
+			if err != nil && !errors.As(err, &paddingErr) {
+				t.Errorf("pkcs7Unpad() error = %T, want *PaddingError", err)
+			}
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("pkcs7Unpad() got = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func Test_Pkcs7Validate(t *testing.T) {
+	type args struct {
+		input []byte
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []byte
+		wantErr bool
+	}{
+		{
+			name:    "Test Pkcs7Validate run successfully",
+			args:    args{input: []byte("ICE ICE BABY\x04\x04\x04\x04")},
+			want:    []byte("ICE ICE BABY"),
+			wantErr: false,
+		},
+		{
+			name:    "Test Pkcs7Validate with a whole block of padding",
+			args:    args{input: append([]byte("YELLOW SUBMARINE"), bytes.Repeat([]byte{16}, 16)...)},
+			want:    []byte("YELLOW SUBMARINE"),
+			wantErr: false,
+		},
+		{
+			name:    "Should throw error on padding bytes with the wrong value",
+			args:    args{input: []byte("ICE ICE BABY\x05\x05\x05\x05")},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "Should throw error on different padding bytes",
+			args:    args{input: []byte("ICE ICE BABY\x01\x02\x03\x04")},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "Should throw error on a partial block",
+			args:    args{input: []byte("ICE ICE BABY\x03\x03\x03")},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "Should throw error on empty input",
+			args:    args{input: []byte{}},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Pkcs7Validate(tt.args.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Pkcs7Validate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			var paddingErr *PaddingError
+			if err != nil && !errors.As(err, &paddingErr) {
+				t.Errorf("Pkcs7Validate() error = %T, want *PaddingError", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Pkcs7Validate() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
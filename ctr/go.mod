@@ -1,3 +1,7 @@
 module ctr
 
 go 1.20
+
+require github.com/japananh/crypto v0.0.0
+
+replace github.com/japananh/crypto => ../
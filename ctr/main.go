@@ -2,62 +2,55 @@ package main
 
 import (
 	"crypto/aes"
-	"crypto/cipher"
-	"crypto/rand"
 	"fmt"
+
+	"github.com/japananh/crypto/modes"
 )
 
-// generateAESKey generates an AES key, either 16, 24, or 32 bytes to select AES-128, AES-192, or AES-256
-func generateAESKey(size int) ([]byte, error) {
-	if size == 16 || size == 24 || size == 32 {
-		key := make([]byte, size)
-		if _, err := rand.Read(key); err != nil {
-			return nil, err
-		}
-		return key, nil
+// ivGuard remembers the IVs used by encrypt and encryptWithIV, and rejects an IV equal to an earlier one.
+// It does not detect different IVs whose counter ranges overlap
+var ivGuard = modes.NewMemoryIVGuard(1 << 16)
+
+// encrypt encrypts plaintext to ciphertext using CTR mode and returns IV || ciphertext
+func encrypt(key, plaintext []byte) ([]byte, error) {
+	mode, err := newGuardedMode(key)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil, fmt.Errorf("AES key size must be 16, 24 or 32 bytes")
+	return mode.Encrypt(plaintext)
 }
 
-// encrypt encrypts plaintext to ciphertext using CTR mode
-func encrypt(plaintext, key []byte) ([]byte, error) {
-	// Create AES encryption block
-	block, err := aes.NewCipher(key)
+// encryptWithIV is like encrypt, but uses the given IV. It fails with modes.ErrIVReused if the IV was already
+// used with the same key.
+func encryptWithIV(key, iv, plaintext []byte) ([]byte, error) {
+	mode, err := newGuardedMode(key)
 	if err != nil {
 		return nil, err
 	}
 
-	ciphertext := make([]byte, aes.BlockSize+len(plaintext))
-	iv := ciphertext[:aes.BlockSize]
-	if _, err := rand.Read(iv); err != nil {
+	return mode.EncryptWithIV(iv, plaintext)
+}
+
+// newGuardedMode returns CTR mode for the given key, checked by ivGuard
+func newGuardedMode(key []byte) (modes.Mode, error) {
+	// Create AES encryption block
+	block, err := aes.NewCipher(key)
+	if err != nil {
 		return nil, err
 	}
 
-	stream := cipher.NewCTR(block, iv)
-	stream.XORKeyStream(ciphertext[aes.BlockSize:], plaintext)
-
-	return ciphertext, nil
+	return modes.WithIVGuard(modes.NewCTR(block), ivGuard)
 }
 
-// decrypt decrypts ciphertext to plaintext using CTR mode
-func decrypt(ciphertext, key []byte) ([]byte, error) {
-	if len(ciphertext) < aes.BlockSize {
-		return nil, fmt.Errorf("ciphertext too short")
-	}
-
+// decrypt decrypts IV || ciphertext using CTR mode
+func decrypt(key, ciphertext []byte) ([]byte, error) {
 	block, err := aes.NewCipher(key)
 	if err != nil {
 		return nil, err
 	}
 
-	iv := ciphertext[:aes.BlockSize]
-	ciphertext = ciphertext[aes.BlockSize:]
-
-	stream := cipher.NewCTR(block, iv)
-	stream.XORKeyStream(ciphertext, ciphertext)
-
-	return ciphertext, nil
+	return modes.NewCTR(block).Decrypt(ciphertext)
 }
 
 func main() {
@@ -65,19 +58,19 @@ func main() {
 
 	fmt.Printf("Plaintext: %s\n", plaintext)
 
-	key, err := generateAESKey(16)
+	key, err := modes.GenerateAESKey(16)
 	if err != nil {
 		panic(err)
 	}
 
-	ciphertext, err := encrypt(plaintext, key)
+	ciphertext, err := encrypt(key, plaintext)
 	if err != nil {
 		panic(err)
 	}
 
 	fmt.Printf("Ciphertext: %x\n", ciphertext)
 
-	decrypted, err := decrypt(ciphertext, key)
+	decrypted, err := decrypt(key, ciphertext)
 	if err != nil {
 		panic(err)
 	}
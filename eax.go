@@ -0,0 +1,107 @@
+package crypto
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"fmt"
+)
+
+const (
+	// EAXNonceSize is the nonce size used by NewEAX. EAX accepts nonces of any length,
+	// a full block is the conventional choice.
+	EAXNonceSize = 16
+	// EAXTagSize is the size of the authentication tag appended by EAX.
+	EAXTagSize = 16
+)
+
+// eax implements the EAX mode of Bellare, Rogaway and Wagner: CTR mode for encryption and
+// three tweaked CMACs (OMAC) over the nonce, the associated data and the ciphertext.
+type eax struct {
+	block cipher.Block
+}
+
+// NewEAX returns the given 128-bit block cipher wrapped in EAX mode with a 16-byte nonce and a 16-byte tag.
+func NewEAX(b cipher.Block) (cipher.AEAD, error) {
+	if b.BlockSize() != macBlockSize {
+		return nil, fmt.Errorf("eax: block size must be %d bytes, got %d", macBlockSize, b.BlockSize())
+	}
+
+	return &eax{block: b}, nil
+}
+
+func (e *eax) NonceSize() int {
+	return EAXNonceSize
+}
+
+func (e *eax) Overhead() int {
+	return EAXTagSize
+}
+
+func (e *eax) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != EAXNonceSize {
+		panic("crypto/eax: incorrect nonce length given to EAX")
+	}
+
+	ret, out := sliceForAppend(dst, len(plaintext)+EAXTagSize)
+
+	// N' = OMAC^0(N) is both the CTR initial counter and part of the tag
+	n := e.omac(0, nonce)
+	cipher.NewCTR(e.block, n[:]).XORKeyStream(out, plaintext)
+
+	tag := e.tag(n, out[:len(plaintext)], additionalData)
+	copy(out[len(plaintext):], tag[:])
+
+	return ret
+}
+
+func (e *eax) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != EAXNonceSize {
+		panic("crypto/eax: incorrect nonce length given to EAX")
+	}
+	if len(ciphertext) < EAXTagSize {
+		return nil, errOpen
+	}
+
+	tag := ciphertext[len(ciphertext)-EAXTagSize:]
+	ciphertext = ciphertext[:len(ciphertext)-EAXTagSize]
+
+	// EAX authenticates the ciphertext, so the tag is checked before anything is decrypted
+	n := e.omac(0, nonce)
+	expectedTag := e.tag(n, ciphertext, additionalData)
+	if subtle.ConstantTimeCompare(expectedTag[:], tag) != 1 {
+		return nil, errOpen
+	}
+
+	ret, out := sliceForAppend(dst, len(ciphertext))
+	cipher.NewCTR(e.block, n[:]).XORKeyStream(out, ciphertext)
+
+	return ret, nil
+}
+
+// tag computes Tag = N' xor OMAC^1(H) xor OMAC^2(C).
+func (e *eax) tag(n [macBlockSize]byte, ciphertext, additionalData []byte) [macBlockSize]byte {
+	h := e.omac(1, additionalData)
+	c := e.omac(2, ciphertext)
+
+	var tag [macBlockSize]byte
+	subtle.XORBytes(tag[:], n[:], h[:])
+	subtle.XORBytes(tag[:], tag[:], c[:])
+
+	return tag
+}
+
+// omac computes the tweaked CMAC OMAC^t(M) = CMAC([t]_128 || M).
+func (e *eax) omac(t byte, data []byte) [macBlockSize]byte {
+	// NewCMAC only fails for block sizes NewEAX already rejected
+	mac, _ := NewCMAC(e.block)
+
+	var tweak [macBlockSize]byte
+	tweak[macBlockSize-1] = t
+	mac.Write(tweak[:])
+	mac.Write(data)
+
+	var sum [macBlockSize]byte
+	mac.Sum(sum[:0])
+
+	return sum
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"fmt"
+
+	"github.com/japananh/crypto"
+	"github.com/japananh/crypto/modes"
+)
+
+// newAEAD returns AES in EAX mode for the given key
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return crypto.NewEAX(block)
+}
+
+// encrypt encrypts and authenticates plaintext using EAX mode. The nonce is prepended to the ciphertext.
+func encrypt(key, plaintext, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return modes.SealWithRandomNonce(aead, plaintext, additionalData)
+}
+
+// decrypt verifies and decrypts ciphertext using EAX mode
+func decrypt(key, ciphertext, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return modes.OpenWithNonce(aead, ciphertext, additionalData)
+}
+
+func main() {
+	// EAX needs no padding, it combines CTR mode encryption with CMAC authentication
+	plaintext := []byte("This is a sample message to be encrypted using EAX mode.")
+
+	// Additional data is authenticated but not encrypted, e.g. a message header
+	additionalData := []byte("message-id: 42")
+
+	key, err := modes.GenerateAESKey(16)
+	if err != nil {
+		panic(err)
+	}
+
+	ciphertext, err := encrypt(key, plaintext, additionalData)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("Plaintext: %s\n", plaintext)
+	fmt.Printf("Ciphertext: %x\n", ciphertext)
+
+	decrypted, err := decrypt(key, ciphertext, additionalData)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("Decrypted: %s\n", decrypted)
+
+	// Any modification of the ciphertext is detected
+	ciphertext[len(ciphertext)-1] ^= 1
+	if _, err := decrypt(key, ciphertext, additionalData); err != nil {
+		fmt.Printf("Tampered ciphertext: %v\n", err)
+	}
+}
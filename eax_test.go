@@ -0,0 +1,110 @@
+package crypto_test
+
+import (
+	"crypto/aes"
+	"encoding/hex"
+	"strings"
+
+	"github.com/japananh/crypto"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("crypto - eax", func() {
+	decodeHex := func(s string) []byte {
+		b, err := hex.DecodeString(s)
+		Expect(err).NotTo(HaveOccurred())
+		return b
+	}
+
+	// Test vectors from "The EAX Mode of Operation" by Bellare, Rogaway and Wagner, Appendix E
+	eaxTestVectors := []struct {
+		msg        string
+		key        string
+		nonce      string
+		header     string
+		ciphertext string
+	}{
+		{
+			msg:        "",
+			key:        "233952DEE4D5ED5F9B9C6D6FF80FF478",
+			nonce:      "62EC67F9C3A4A407FCB2A8C49031A8B3",
+			header:     "6BFB914FD07EAE6B",
+			ciphertext: "E037830E8389F27B025A2D6527E79D01",
+		},
+		{
+			msg:        "F7FB",
+			key:        "91945D3F4DCBEE0BF45EF52255F095A4",
+			nonce:      "BECAF043B0A23D843194BA972C66DEBD",
+			header:     "FA3BFD4806EB53FA",
+			ciphertext: "19DD5C4C9331049D0BDAB0277408F67967E5",
+		},
+		{
+			msg:        "1A47CB4933",
+			key:        "01F74AD64077F2E704C0F60ADA3DD523",
+			nonce:      "70C3DB4F0D26368400A10ED05D2BFF5E",
+			header:     "234A3463C1264AC6",
+			ciphertext: "D851D5BAE03A59F238A23E39199DC9266626C40F80",
+		},
+	}
+
+	Describe("Seal - Open", func() {
+		for _, tt := range eaxTestVectors {
+			tt := tt
+			It("should match the test vector with key "+tt.key, func() {
+				block, err := aes.NewCipher(decodeHex(tt.key))
+				Expect(err).NotTo(HaveOccurred())
+				aead, err := crypto.NewEAX(block)
+				Expect(err).NotTo(HaveOccurred())
+
+				nonce := decodeHex(tt.nonce)
+				header := decodeHex(tt.header)
+				plaintext := decodeHex(tt.msg)
+
+				ciphertext := aead.Seal(nil, nonce, plaintext, header)
+				Expect(strings.ToUpper(hex.EncodeToString(ciphertext))).To(Equal(tt.ciphertext))
+
+				decrypted, err := aead.Open(nil, nonce, ciphertext, header)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(hex.EncodeToString(decrypted)).To(Equal(hex.EncodeToString(plaintext)))
+			})
+		}
+
+		It("should encrypt and decrypt a long message", func() {
+			block, err := aes.NewCipher(randomBytes(24))
+			Expect(err).NotTo(HaveOccurred())
+			aead, err := crypto.NewEAX(block)
+			Expect(err).NotTo(HaveOccurred())
+
+			nonce := randomBytes(aead.NonceSize())
+			plaintext := randomBytes(4099)
+
+			ciphertext := aead.Seal(nil, nonce, plaintext, nil)
+			decrypted, err := aead.Open(nil, nonce, ciphertext, nil)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decrypted).To(Equal(plaintext))
+		})
+
+		It("should reject a tampered ciphertext, tag or associated data", func() {
+			block, err := aes.NewCipher(randomBytes(16))
+			Expect(err).NotTo(HaveOccurred())
+			aead, err := crypto.NewEAX(block)
+			Expect(err).NotTo(HaveOccurred())
+
+			nonce := randomBytes(aead.NonceSize())
+			ciphertext := aead.Seal(nil, nonce, []byte("attack at dawn"), []byte("header"))
+
+			for i := range ciphertext {
+				tampered := append([]byte(nil), ciphertext...)
+				tampered[i] ^= 1
+				_, err := aead.Open(nil, nonce, tampered, []byte("header"))
+				Expect(err).To(HaveOccurred())
+			}
+
+			_, err = aead.Open(nil, nonce, ciphertext, nil)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,4 @@
+package crypto
+
+// CMACSubkeys exposes the CMAC subkey generation to the external test package.
+var CMACSubkeys = cmacSubkeys
@@ -0,0 +1,259 @@
+package crypto
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	// GCMStandardNonceSize is the 96-bit nonce recommended by NIST SP 800-38D.
+	GCMStandardNonceSize = 12
+	// GCMTagSize is the size of the authentication tag appended by GCM.
+	GCMTagSize = 16
+)
+
+// GCMTraceKind names an intermediate value of a GCM computation.
+type GCMTraceKind string
+
+const (
+	// GCMTraceHashKey is the GHASH key H = E(K, 0^128).
+	GCMTraceHashKey GCMTraceKind = "H"
+	// GCMTracePreCounter is the pre-counter block J0 derived from the nonce.
+	GCMTracePreCounter GCMTraceKind = "J0"
+	// GCMTraceCounter is counter block i, starting with inc32(J0) for i = 1.
+	GCMTraceCounter GCMTraceKind = "CB"
+	// GCMTraceKeystream is E(K, CB_i), the keystream block XORed with block i of the data.
+	GCMTraceKeystream GCMTraceKind = "E(K,CB)"
+	// GCMTraceGHASH is the GHASH accumulator X_i after absorbing block i of A || C || len(A) || len(C).
+	GCMTraceGHASH GCMTraceKind = "X"
+	// GCMTraceTag is the final tag T = E(K, J0) xor S.
+	GCMTraceTag GCMTraceKind = "T"
+)
+
+// GCMTracer is called with every intermediate block of a Seal or Open call.
+// index numbers counter blocks and GHASH steps from 1, and is 0 for the single-valued kinds.
+type GCMTracer func(kind GCMTraceKind, index int, block [AESBlockSize]byte)
+
+// gcm is a GCM implementation that favours readability over speed: GHASH multiplies in GF(2^128)
+// one bit at a time, exactly as in NIST SP 800-38D, Algorithm 1. It is not constant-time.
+type gcm struct {
+	block     cipher.Block
+	h         [AESBlockSize]byte
+	nonceSize int
+	tracer    GCMTracer
+}
+
+// NewGCM returns the given 128-bit block cipher wrapped in Galois Counter Mode with the standard nonce length.
+// Unlike cipher.NewGCM, every step of the computation is plain Go that can be read and traced.
+func NewGCM(b cipher.Block) (cipher.AEAD, error) {
+	return NewGCMWithTracer(b, GCMStandardNonceSize, nil)
+}
+
+// NewGCMWithNonceSize is like NewGCM, but accepts nonces of the given length. Only use it to interoperate with
+// existing systems: nonces of any length other than 12 bytes are hashed with GHASH to build J0.
+func NewGCMWithNonceSize(b cipher.Block, size int) (cipher.AEAD, error) {
+	return NewGCMWithTracer(b, size, nil)
+}
+
+// NewGCMWithTracer is like NewGCMWithNonceSize, and calls tracer with the hash key, counter blocks,
+// keystream blocks, GHASH steps and tag of every Seal and Open call. A nil tracer disables tracing.
+func NewGCMWithTracer(b cipher.Block, nonceSize int, tracer GCMTracer) (cipher.AEAD, error) {
+	if b.BlockSize() != AESBlockSize {
+		return nil, fmt.Errorf("gcm: block size must be %d bytes, got %d", AESBlockSize, b.BlockSize())
+	}
+	if nonceSize <= 0 {
+		return nil, fmt.Errorf("gcm: nonce size must be positive, got %d", nonceSize)
+	}
+
+	g := &gcm{block: b, nonceSize: nonceSize, tracer: tracer}
+	b.Encrypt(g.h[:], g.h[:])
+
+	return g, nil
+}
+
+func (g *gcm) NonceSize() int {
+	return g.nonceSize
+}
+
+func (g *gcm) Overhead() int {
+	return GCMTagSize
+}
+
+func (g *gcm) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != g.nonceSize {
+		panic("crypto/gcm: incorrect nonce length given to GCM")
+	}
+
+	ret, out := sliceForAppend(dst, len(plaintext)+GCMTagSize)
+
+	j0 := g.preCounter(nonce)
+	g.counterMode(out, plaintext, j0)
+
+	tag := g.tag(j0, additionalData, out[:len(plaintext)])
+	copy(out[len(plaintext):], tag[:])
+
+	return ret
+}
+
+func (g *gcm) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != g.nonceSize {
+		panic("crypto/gcm: incorrect nonce length given to GCM")
+	}
+	if len(ciphertext) < GCMTagSize {
+		return nil, errOpen
+	}
+
+	tag := ciphertext[len(ciphertext)-GCMTagSize:]
+	ciphertext = ciphertext[:len(ciphertext)-GCMTagSize]
+
+	// GCM authenticates the ciphertext, so the tag is checked before anything is decrypted
+	j0 := g.preCounter(nonce)
+	expectedTag := g.tag(j0, additionalData, ciphertext)
+	if subtle.ConstantTimeCompare(expectedTag[:], tag) != 1 {
+		return nil, errOpen
+	}
+
+	ret, out := sliceForAppend(dst, len(ciphertext))
+	g.counterMode(out, ciphertext, j0)
+
+	return ret, nil
+}
+
+// preCounter derives the pre-counter block J0 from the nonce (SP 800-38D, section 7.1, step 2).
+func (g *gcm) preCounter(nonce []byte) [AESBlockSize]byte {
+	g.trace(GCMTraceHashKey, 0, g.h)
+
+	var j0 [AESBlockSize]byte
+	if len(nonce) == GCMStandardNonceSize {
+		// J0 = IV || 0^31 || 1
+		copy(j0[:], nonce)
+		j0[AESBlockSize-1] = 1
+	} else {
+		// J0 = GHASH(IV || 0^(s+64) || [len(IV)]_64)
+		var lengths [AESBlockSize]byte
+		binary.BigEndian.PutUint64(lengths[8:], uint64(len(nonce))*8)
+		g.ghashBlocks(&j0, nonce, false)
+		g.ghashBlocks(&j0, lengths[:], false)
+	}
+	g.trace(GCMTracePreCounter, 0, j0)
+
+	return j0
+}
+
+// counterMode runs GCTR starting at inc32(J0). The same function encrypts and decrypts.
+func (g *gcm) counterMode(dst, src []byte, j0 [AESBlockSize]byte) {
+	counter := j0
+
+	for i := 1; len(src) > 0; i++ {
+		inc32(&counter)
+		g.trace(GCMTraceCounter, i, counter)
+
+		var keystream [AESBlockSize]byte
+		g.block.Encrypt(keystream[:], counter[:])
+		g.trace(GCMTraceKeystream, i, keystream)
+
+		n := subtle.XORBytes(dst, src, keystream[:])
+		dst = dst[n:]
+		src = src[n:]
+	}
+}
+
+// tag computes T = E(K, J0) xor GHASH(A || 0^v || C || 0^u || [len(A)]_64 || [len(C)]_64).
+func (g *gcm) tag(j0 [AESBlockSize]byte, additionalData, ciphertext []byte) [AESBlockSize]byte {
+	var lengths [AESBlockSize]byte
+	binary.BigEndian.PutUint64(lengths[:8], uint64(len(additionalData))*8)
+	binary.BigEndian.PutUint64(lengths[8:], uint64(len(ciphertext))*8)
+
+	var s [AESBlockSize]byte
+	step := g.ghashBlocks(&s, additionalData, true)
+	step += g.ghashBlocks(&s, ciphertext, true)
+	g.ghashStep(&s, lengths[:], step+1, true)
+
+	var tag [AESBlockSize]byte
+	g.block.Encrypt(tag[:], j0[:])
+	subtle.XORBytes(tag[:], tag[:], s[:])
+	g.trace(GCMTraceTag, 0, tag)
+
+	return tag
+}
+
+// ghashBlocks absorbs data, zero-padded to a whole number of blocks, into the GHASH accumulator y.
+// It returns the number of blocks absorbed.
+func (g *gcm) ghashBlocks(y *[AESBlockSize]byte, data []byte, trace bool) int {
+	steps := 0
+	for len(data) > 0 {
+		var block [AESBlockSize]byte
+		n := copy(block[:], data)
+		data = data[n:]
+
+		steps++
+		g.ghashStep(y, block[:], steps, trace)
+	}
+	return steps
+}
+
+// ghashStep computes X_i = (X_(i-1) xor block) · H.
+func (g *gcm) ghashStep(y *[AESBlockSize]byte, block []byte, index int, trace bool) {
+	subtle.XORBytes(y[:], y[:], block)
+	*y = GF128Mul(*y, g.h)
+	if trace {
+		g.trace(GCMTraceGHASH, index, *y)
+	}
+}
+
+func (g *gcm) trace(kind GCMTraceKind, index int, block [AESBlockSize]byte) {
+	if g.tracer != nil {
+		g.tracer(kind, index, block)
+	}
+}
+
+// GHASH computes GHASH_H(A || 0^v || C || 0^u || [len(A)]_64 || [len(C)]_64), the polynomial in H that GCM
+// encrypts with E(K, J0) to produce the tag.
+func GHASH(h [AESBlockSize]byte, additionalData, ciphertext []byte) [AESBlockSize]byte {
+	g := &gcm{h: h}
+
+	var lengths [AESBlockSize]byte
+	binary.BigEndian.PutUint64(lengths[:8], uint64(len(additionalData))*8)
+	binary.BigEndian.PutUint64(lengths[8:], uint64(len(ciphertext))*8)
+
+	var s [AESBlockSize]byte
+	g.ghashBlocks(&s, additionalData, false)
+	g.ghashBlocks(&s, ciphertext, false)
+	g.ghashStep(&s, lengths[:], 0, false)
+
+	return s
+}
+
+// GF128Mul multiplies two elements of GF(2^128) as defined for GCM (SP 800-38D, section 6.3, Algorithm 1).
+// GCM uses a reflected bit order: the first bit of the first byte is the coefficient of x^0,
+// and the field is reduced by x^128 + x^7 + x^2 + x + 1.
+func GF128Mul(x, y [AESBlockSize]byte) [AESBlockSize]byte {
+	var z [AESBlockSize]byte
+	v := y
+
+	for i := 0; i < 128; i++ {
+		// If bit i of x is set, add V to the result
+		if x[i/8]&(0x80>>(i%8)) != 0 {
+			subtle.XORBytes(z[:], z[:], v[:])
+		}
+
+		// V = V · x: a right shift in the reflected order, reduced by R = 11100001 || 0^120
+		lsb := v[AESBlockSize-1] & 1
+		for j := AESBlockSize - 1; j > 0; j-- {
+			v[j] = v[j]>>1 | v[j-1]<<7
+		}
+		v[0] >>= 1
+		if lsb != 0 {
+			v[0] ^= 0xe1
+		}
+	}
+
+	return z
+}
+
+// inc32 increments the rightmost 32 bits of a counter block modulo 2^32.
+func inc32(counter *[AESBlockSize]byte) {
+	binary.BigEndian.PutUint32(counter[12:], binary.BigEndian.Uint32(counter[12:])+1)
+}
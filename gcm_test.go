@@ -0,0 +1,217 @@
+package crypto_test
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/japananh/crypto"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("crypto - gcm", func() {
+	decodeHex := func(s string) []byte {
+		b, err := hex.DecodeString(s)
+		Expect(err).NotTo(HaveOccurred())
+		return b
+	}
+
+	const (
+		tcKey       = "feffe9928665731c6d6a8f9467308308"
+		tcPlaintext = "d9313225f88406e5a55909c5aff5269a86a7a9531534f7da2e4c303d8a318a721c3c0c95956809532fcf0e2449a6b525b16aedf5aa0de657ba637b391aafd255"
+		tcAAD       = "feedfacedeadbeeffeedfacedeadbeefabaddad2"
+	)
+
+	// Test cases 1 to 6 of "The Galois/Counter Mode of Operation (GCM)" submitted to NIST by McGrew and Viega,
+	// followed by samples of the NIST GCMVS gcmEncryptExtIV vectors
+	gcmTestVectors := []struct {
+		name       string
+		key        string
+		nonce      string
+		plaintext  string
+		ad         string
+		ciphertext string
+		tag        string
+	}{
+		{name: "Test Case 1", key: "00000000000000000000000000000000", nonce: "000000000000000000000000", tag: "58e2fccefa7e3061367f1d57a4e7455a"},
+		{name: "Test Case 2", key: "00000000000000000000000000000000", nonce: "000000000000000000000000", plaintext: "00000000000000000000000000000000", ciphertext: "0388dace60b6a392f328c2b971b2fe78", tag: "ab6e47d42cec13bdf53a67b21257bddf"},
+		{
+			name:       "Test Case 3",
+			key:        tcKey,
+			nonce:      "cafebabefacedbaddecaf888",
+			plaintext:  tcPlaintext,
+			ciphertext: "42831ec2217774244b7221b784d0d49ce3aa212f2c02a4e035c17e2329aca12e21d514b25466931c7d8f6a5aac84aa051ba30b396a0aac973d58e091473f5985",
+			tag:        "4d5c2af327cd64a62cf35abd2ba6fab4",
+		},
+		{
+			name:       "Test Case 4",
+			key:        tcKey,
+			nonce:      "cafebabefacedbaddecaf888",
+			plaintext:  tcPlaintext[:120],
+			ad:         tcAAD,
+			ciphertext: "42831ec2217774244b7221b784d0d49ce3aa212f2c02a4e035c17e2329aca12e21d514b25466931c7d8f6a5aac84aa051ba30b396a0aac973d58e091",
+			tag:        "5bc94fbc3221a5db94fae95ae7121a47",
+		},
+		{
+			name:       "Test Case 5 (64-bit nonce)",
+			key:        tcKey,
+			nonce:      "cafebabefacedbad",
+			plaintext:  tcPlaintext[:120],
+			ad:         tcAAD,
+			ciphertext: "61353b4c2806934a777ff51fa22a4755699b2a714fcdc6f83766e5f97b6c742373806900e49f24b22b097544d4896b424989b5e1ebac0f07c23f4598",
+			tag:        "3612d2e79e3b0785561be14aaca2fccb",
+		},
+		{
+			name:       "Test Case 6 (480-bit nonce)",
+			key:        tcKey,
+			nonce:      "9313225df88406e555909c5aff5269aa6a7a9538534f7da1e4c303d2a318a728c3c0c95156809539fcf0e2429a6b525416aedbf5a0de6a57a637b39b",
+			plaintext:  tcPlaintext[:120],
+			ad:         tcAAD,
+			ciphertext: "8ce24998625615b603a033aca13fb894be9112a5c3a211a8ba262a3cca7e2ca701e4a9a4fba43c90ccdcb281d48c7c6fd62875d2aca417034c34aee5",
+			tag:        "619cc5aefffe0bfa462af43c1699d050",
+		},
+		{name: "GCMVS AES-128 empty plaintext", key: "11754cd72aec309bf52f7687212e8957", nonce: "3c819d9a9bed087615030b65", tag: "250327c674aaf477aef2675748cf6971"},
+		{name: "GCMVS AES-192 empty plaintext", key: "e2e001a36c60d2bf40d69ff5b2b1161ea218db263be16a4e", nonce: "3c819d9a9bed087615030b65", tag: "c7b8da1fe2e3dccc4071ba92a0a57ba8"},
+		{name: "GCMVS AES-256 empty plaintext", key: "5394e890d37ba55ec9d5f327f15680f6a63ef5279c79331643ad0af6d2623525", nonce: "3c819d9a9bed087615030b65", tag: "d9b260d4bc4630733ffb642f5ce45726"},
+		{name: "GCMVS AES-128 1-byte AAD", key: "fbe3467cc254f81be8e78d765a2e6333", nonce: "c6697351ff4aec29cdbaabf2", ad: "67", tag: "3659cdc25288bf499ac736c03bfc1159"},
+		{name: "GCMVS AES-128 13-byte AAD", key: "8a7f9d80d08ad0bd5a20fb689c88f9fc", nonce: "88b7b27d800937fda4f47301", ad: "50edd0503e0d7b8c91608eb5a1", tag: "ed6f65322a4740011f91d2aae22dd44e"},
+	}
+
+	Describe("Seal - Open", func() {
+		for _, tt := range gcmTestVectors {
+			tt := tt
+			It("should match "+tt.name, func() {
+				block, err := aes.NewCipher(decodeHex(tt.key))
+				Expect(err).NotTo(HaveOccurred())
+
+				nonce := decodeHex(tt.nonce)
+				aead, err := crypto.NewGCMWithNonceSize(block, len(nonce))
+				Expect(err).NotTo(HaveOccurred())
+
+				plaintext, ad := decodeHex(tt.plaintext), decodeHex(tt.ad)
+				sealed := aead.Seal(nil, nonce, plaintext, ad)
+				Expect(hex.EncodeToString(sealed)).To(Equal(tt.ciphertext + tt.tag))
+
+				opened, err := aead.Open(nil, nonce, sealed, ad)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(hex.EncodeToString(opened)).To(Equal(tt.plaintext))
+			})
+		}
+
+		for _, nonceSize := range []int{12, 8, 60} {
+			nonceSize := nonceSize
+			It(fmt.Sprintf("should match cipher.NewGCM with a %d-byte nonce", nonceSize), func() {
+				for _, length := range []int{0, 1, 15, 16, 17, 100, 1000} {
+					block, err := aes.NewCipher(randomBytes(32))
+					Expect(err).NotTo(HaveOccurred())
+
+					ours, err := crypto.NewGCMWithNonceSize(block, nonceSize)
+					Expect(err).NotTo(HaveOccurred())
+					stdlib, err := cipher.NewGCMWithNonceSize(block, nonceSize)
+					Expect(err).NotTo(HaveOccurred())
+
+					nonce := randomBytes(nonceSize)
+					plaintext := randomBytes(length)
+					ad := randomBytes(length % 37)
+
+					sealed := ours.Seal(nil, nonce, plaintext, ad)
+					Expect(sealed).To(Equal(stdlib.Seal(nil, nonce, plaintext, ad)))
+
+					opened, err := stdlib.Open(nil, nonce, sealed, ad)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(opened).To(Equal(plaintext))
+				}
+			})
+		}
+
+		It("should encrypt and decrypt in place with the pure-Go AES", func() {
+			block, err := crypto.NewAESCipher(randomBytes(16))
+			Expect(err).NotTo(HaveOccurred())
+			aead, err := crypto.NewGCM(block)
+			Expect(err).NotTo(HaveOccurred())
+
+			nonce := randomBytes(aead.NonceSize())
+			plaintext := randomBytes(50)
+			buf := append([]byte(nil), plaintext...)
+
+			sealed := aead.Seal(buf[:0], nonce, buf, nil)
+			opened, err := aead.Open(sealed[:0], nonce, sealed, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(opened).To(Equal(plaintext))
+		})
+
+		It("should reject a tampered ciphertext, tag or associated data", func() {
+			block, err := aes.NewCipher(randomBytes(16))
+			Expect(err).NotTo(HaveOccurred())
+			aead, err := crypto.NewGCM(block)
+			Expect(err).NotTo(HaveOccurred())
+
+			nonce := randomBytes(aead.NonceSize())
+			sealed := aead.Seal(nil, nonce, []byte("attack at dawn"), []byte("header"))
+
+			for i := range sealed {
+				tampered := append([]byte(nil), sealed...)
+				tampered[i] ^= 0x80
+				_, err := aead.Open(nil, nonce, tampered, []byte("header"))
+				Expect(err).To(HaveOccurred())
+			}
+
+			_, err = aead.Open(nil, nonce, sealed, nil)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("NewGCMWithTracer", func() {
+		It("should record the intermediate values of Test Case 2", func() {
+			type traceEntry struct {
+				kind  crypto.GCMTraceKind
+				index int
+				block string
+			}
+
+			var trace []traceEntry
+			block, err := aes.NewCipher(make([]byte, 16))
+			Expect(err).NotTo(HaveOccurred())
+			aead, err := crypto.NewGCMWithTracer(block, crypto.GCMStandardNonceSize, func(kind crypto.GCMTraceKind, index int, block [crypto.AESBlockSize]byte) {
+				trace = append(trace, traceEntry{kind: kind, index: index, block: hex.EncodeToString(block[:])})
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			aead.Seal(nil, make([]byte, 12), make([]byte, 16), nil)
+
+			Expect(trace).To(Equal([]traceEntry{
+				{kind: crypto.GCMTraceHashKey, index: 0, block: "66e94bd4ef8a2c3b884cfa59ca342b2e"},
+				{kind: crypto.GCMTracePreCounter, index: 0, block: "00000000000000000000000000000001"},
+				{kind: crypto.GCMTraceCounter, index: 1, block: "00000000000000000000000000000002"},
+				{kind: crypto.GCMTraceKeystream, index: 1, block: "0388dace60b6a392f328c2b971b2fe78"},
+				{kind: crypto.GCMTraceGHASH, index: 1, block: "5e2ec746917062882c85b0685353deb7"},
+				{kind: crypto.GCMTraceGHASH, index: 2, block: "f38cbb1ad69223dcc3457ae5b6b0f885"},
+				{kind: crypto.GCMTraceTag, index: 0, block: "ab6e47d42cec13bdf53a67b21257bddf"},
+			}))
+		})
+	})
+
+	Describe("GHASH - GF128Mul", func() {
+		It("should treat 0x80 00 ... 00 as the multiplicative identity", func() {
+			var one [crypto.AESBlockSize]byte
+			one[0] = 0x80
+
+			var x [crypto.AESBlockSize]byte
+			copy(x[:], randomBytes(crypto.AESBlockSize))
+
+			Expect(crypto.GF128Mul(x, one)).To(Equal(x))
+			Expect(crypto.GF128Mul(one, x)).To(Equal(x))
+		})
+
+		It("should reproduce the Test Case 2 GHASH output", func() {
+			var h [crypto.AESBlockSize]byte
+			copy(h[:], decodeHex("66e94bd4ef8a2c3b884cfa59ca342b2e"))
+
+			s := crypto.GHASH(h, nil, decodeHex("0388dace60b6a392f328c2b971b2fe78"))
+			Expect(hex.EncodeToString(s[:])).To(Equal("f38cbb1ad69223dcc3457ae5b6b0f885"))
+		})
+	})
+})
@@ -0,0 +1,28 @@
+package modes
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+)
+
+// SealWithRandomNonce encrypts and authenticates plaintext under a fresh random nonce and returns
+// nonce || ciphertext || tag, the AEAD counterpart of the IV-prefix framing of Mode.
+func SealWithRandomNonce(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	// Never reuse a nonce with the same key
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// OpenWithNonce verifies and decrypts nonce || ciphertext || tag as produced by SealWithRandomNonce.
+func OpenWithNonce(aead cipher.AEAD, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, ErrCiphertextTooShort
+	}
+
+	nonce := ciphertext[:aead.NonceSize()]
+	return aead.Open(nil, nonce, ciphertext[aead.NonceSize():], additionalData)
+}
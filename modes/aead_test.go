@@ -0,0 +1,69 @@
+package modes_test
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+
+	"github.com/japananh/crypto"
+	"github.com/japananh/crypto/modes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("modes - aead", func() {
+	newAEADs := map[string]func(cipher.Block) (cipher.AEAD, error){
+		"GCM": cipher.NewGCM,
+		"OCB": crypto.NewOCB,
+		"EAX": crypto.NewEAX,
+	}
+
+	plaintext := []byte("This is a sample message to be encrypted.")
+	additionalData := []byte("message-id: 42")
+
+	for name, newAEAD := range newAEADs {
+		name, newAEAD := name, newAEAD
+
+		Context(name, func() {
+			var aead cipher.AEAD
+
+			BeforeEach(func() {
+				block, err := aes.NewCipher([]byte("YELLOW SUBMARINE"))
+				Expect(err).NotTo(HaveOccurred())
+				aead, err = newAEAD(block)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should round-trip with the nonce prepended to the ciphertext", func() {
+				ciphertext, err := modes.SealWithRandomNonce(aead, plaintext, additionalData)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ciphertext).To(HaveLen(aead.NonceSize() + len(plaintext) + aead.Overhead()))
+
+				other, err := modes.SealWithRandomNonce(aead, plaintext, additionalData)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(other[:aead.NonceSize()]).NotTo(Equal(ciphertext[:aead.NonceSize()]))
+
+				decrypted, err := modes.OpenWithNonce(aead, ciphertext, additionalData)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(decrypted).To(Equal(plaintext))
+			})
+
+			It("should reject tampered ciphertexts and additional data", func() {
+				ciphertext, err := modes.SealWithRandomNonce(aead, plaintext, additionalData)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = modes.OpenWithNonce(aead, ciphertext, []byte("message-id: 43"))
+				Expect(err).To(HaveOccurred())
+
+				ciphertext[len(ciphertext)-1] ^= 1
+				_, err = modes.OpenWithNonce(aead, ciphertext, additionalData)
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("should reject ciphertexts shorter than the nonce", func() {
+				_, err := modes.OpenWithNonce(aead, make([]byte, aead.NonceSize()-1), additionalData)
+				Expect(err).To(MatchError(modes.ErrCiphertextTooShort))
+			})
+		})
+	}
+})
@@ -0,0 +1,77 @@
+package modes
+
+import (
+	"crypto/cipher"
+	"fmt"
+
+	"github.com/japananh/crypto/padding"
+)
+
+// cbcMode is cipher block chaining. A nil padder means plaintexts must be a whole number of blocks.
+type cbcMode struct {
+	block  cipher.Block
+	padder padding.Padder
+}
+
+// NewCBC returns the cipher block chaining mode (CBC) of the given block cipher.
+// Plaintexts are padded with PKCS#7 to a whole number of blocks, and the padding is removed on decryption.
+func NewCBC(b cipher.Block) Mode {
+	return &cbcMode{block: b, padder: padding.PKCS7}
+}
+
+// NewCBCWithPadding is like NewCBC, but pads plaintexts with the given padding scheme.
+func NewCBCWithPadding(b cipher.Block, padder padding.Padder) Mode {
+	return &cbcMode{block: b, padder: padder}
+}
+
+// NewCBCNoPadding is like NewCBC, but does not pad: plaintexts must be a whole number of blocks.
+func NewCBCNoPadding(b cipher.Block) Mode {
+	return &cbcMode{block: b}
+}
+
+func (m *cbcMode) IVSize() int {
+	return m.block.BlockSize()
+}
+
+func (m *cbcMode) Encrypt(plaintext []byte) ([]byte, error) {
+	return encryptWithRandomIV(m, plaintext)
+}
+
+func (m *cbcMode) EncryptWithIV(iv, plaintext []byte) ([]byte, error) {
+	blockSize := m.block.BlockSize()
+	if m.padder != nil {
+		var err error
+		if plaintext, err = m.padder.Pad(plaintext, blockSize); err != nil {
+			return nil, err
+		}
+	} else if len(plaintext)%blockSize != 0 {
+		return nil, fmt.Errorf("modes: CBC plaintext must be a multiple of %d bytes, got %d", blockSize, len(plaintext))
+	}
+
+	ciphertext, body, err := frame(iv, m.IVSize(), len(plaintext))
+	if err != nil {
+		return nil, err
+	}
+
+	cipher.NewCBCEncrypter(m.block, iv).CryptBlocks(body, plaintext)
+
+	return ciphertext, nil
+}
+
+func (m *cbcMode) Decrypt(ciphertext []byte) ([]byte, error) {
+	iv, body, err := unframe(ciphertext, m.IVSize())
+	if err != nil {
+		return nil, err
+	}
+	if len(body)%m.block.BlockSize() != 0 {
+		return nil, fmt.Errorf("modes: CBC ciphertext must be a multiple of %d bytes, got %d", m.block.BlockSize(), len(body))
+	}
+
+	plaintext := make([]byte, len(body))
+	cipher.NewCBCDecrypter(m.block, iv).CryptBlocks(plaintext, body)
+
+	if m.padder != nil {
+		return m.padder.Unpad(plaintext, m.block.BlockSize())
+	}
+	return plaintext, nil
+}
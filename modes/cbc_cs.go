@@ -0,0 +1,145 @@
+package modes
+
+import (
+	"crypto/cipher"
+	"fmt"
+)
+
+// cbcCSMode is CBC with ciphertext stealing, as specified in the addendum to NIST SP 800-38A
+// ("Three Variants of Ciphertext Stealing for CBC Mode"). The ciphertext is exactly as long as the plaintext.
+//
+// The last plaintext block P_n may be partial, with d bytes. It is zero-padded and the message is encrypted
+// with plain CBC; the last two ciphertext blocks C_(n-1) and C_n are then output as C_(n-1)* || C_n,
+// where C_(n-1)* is the first d bytes of C_(n-1). The discarded bytes of C_(n-1) are recovered on decryption
+// from D(C_n), because they encrypted the zero padding of P_n. The variants only differ in how they order
+// the last two blocks:
+//
+//   - CS1 keeps the order C_(n-1)* || C_n.
+//   - CS2 swaps them to C_n || C_(n-1)* when P_n is partial, so the output equals CBC for whole-block messages.
+//   - CS3 always swaps them, the variant used by Kerberos (RFC 3962).
+type cbcCSMode struct {
+	block   cipher.Block
+	variant int
+}
+
+// NewCBCCS1 returns CBC with ciphertext stealing variant CS1 of the given block cipher.
+// Plaintexts must be at least one block long.
+func NewCBCCS1(b cipher.Block) Mode {
+	return &cbcCSMode{block: b, variant: 1}
+}
+
+// NewCBCCS2 returns CBC with ciphertext stealing variant CS2 of the given block cipher.
+// Plaintexts must be at least one block long.
+func NewCBCCS2(b cipher.Block) Mode {
+	return &cbcCSMode{block: b, variant: 2}
+}
+
+// NewCBCCS3 returns CBC with ciphertext stealing variant CS3 of the given block cipher.
+// Plaintexts must be at least one block long.
+func NewCBCCS3(b cipher.Block) Mode {
+	return &cbcCSMode{block: b, variant: 3}
+}
+
+func (m *cbcCSMode) IVSize() int {
+	return m.block.BlockSize()
+}
+
+func (m *cbcCSMode) Encrypt(plaintext []byte) ([]byte, error) {
+	return encryptWithRandomIV(m, plaintext)
+}
+
+func (m *cbcCSMode) EncryptWithIV(iv, plaintext []byte) ([]byte, error) {
+	if err := m.checkLength(len(plaintext)); err != nil {
+		return nil, err
+	}
+
+	ciphertext, body, err := frame(iv, m.IVSize(), len(plaintext))
+	if err != nil {
+		return nil, err
+	}
+
+	blockSize := m.block.BlockSize()
+	n, d := m.split(len(plaintext))
+
+	padded := make([]byte, n*blockSize)
+	copy(padded, plaintext)
+	cipher.NewCBCEncrypter(m.block, iv).CryptBlocks(padded, padded)
+
+	if n == 1 {
+		copy(body, padded)
+		return ciphertext, nil
+	}
+
+	// C_1 .. C_(n-2) are output unchanged
+	copy(body, padded[:(n-2)*blockSize])
+	tail := body[(n-2)*blockSize:]
+	prev, last := padded[(n-2)*blockSize:(n-1)*blockSize], padded[(n-1)*blockSize:]
+
+	if m.swap(d) {
+		copy(tail, last)
+		copy(tail[blockSize:], prev[:d])
+	} else {
+		copy(tail, prev[:d])
+		copy(tail[d:], last)
+	}
+
+	return ciphertext, nil
+}
+
+func (m *cbcCSMode) Decrypt(ciphertext []byte) ([]byte, error) {
+	iv, body, err := unframe(ciphertext, m.IVSize())
+	if err != nil {
+		return nil, err
+	}
+	if err := m.checkLength(len(body)); err != nil {
+		return nil, err
+	}
+
+	blockSize := m.block.BlockSize()
+	n, d := m.split(len(body))
+
+	// Rebuild the plain CBC ciphertext C_1 .. C_n, then decrypt it as usual
+	full := make([]byte, n*blockSize)
+	if n == 1 {
+		copy(full, body)
+	} else {
+		copy(full, body[:(n-2)*blockSize])
+
+		tail := body[(n-2)*blockSize:]
+		var prevStar, last []byte
+		if m.swap(d) {
+			last, prevStar = tail[:blockSize], tail[blockSize:]
+		} else {
+			prevStar, last = tail[:d], tail[d:]
+		}
+
+		// D(C_n) = (P_n || 0) xor C_(n-1), so its last bytes are the bytes of C_(n-1) that were stolen
+		prev := full[(n-2)*blockSize : (n-1)*blockSize]
+		m.block.Decrypt(prev, last)
+		copy(prev, prevStar)
+		copy(full[(n-1)*blockSize:], last)
+	}
+
+	cipher.NewCBCDecrypter(m.block, iv).CryptBlocks(full, full)
+
+	return full[:len(body)], nil
+}
+
+// split returns the number of blocks n of a message and the length d of its last, possibly partial, block.
+func (m *cbcCSMode) split(length int) (n, d int) {
+	blockSize := m.block.BlockSize()
+	n = (length + blockSize - 1) / blockSize
+	return n, length - (n-1)*blockSize
+}
+
+// swap reports whether the last two ciphertext blocks are output as C_n || C_(n-1)*.
+func (m *cbcCSMode) swap(d int) bool {
+	return m.variant == 3 || (m.variant == 2 && d != m.block.BlockSize())
+}
+
+func (m *cbcCSMode) checkLength(length int) error {
+	if length < m.block.BlockSize() {
+		return fmt.Errorf("modes: CBC-CS%d input must be at least %d bytes, got %d", m.variant, m.block.BlockSize(), length)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package modes_test
+
+import (
+	"crypto/aes"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/japananh/crypto/modes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("modes - cbc ciphertext stealing", func() {
+	decodeHex := func(s string) []byte {
+		b, err := hex.DecodeString(s)
+		Expect(err).NotTo(HaveOccurred())
+		return b
+	}
+
+	// The AES-128 examples of RFC 3962, Appendix B, which uses CS3 with a zero IV
+	key := []byte("chicken teriyaki")
+	plaintext := []byte("I would like the General Gau's Chicken, please, and wonton soup.")
+	cs3TestVectors := []struct {
+		length     int
+		ciphertext string
+	}{
+		{length: 17, ciphertext: "c6353568f2bf8cb4d8a580362da7ff7f97"},
+		{length: 31, ciphertext: "fc00783e0efdb2c1d445d4c8eff7ed2297687268d6ecccc0c07b25e25ecfe5"},
+		{length: 32, ciphertext: "39312523a78662d5be7fcbcc98ebf5a897687268d6ecccc0c07b25e25ecfe584"},
+		{length: 47, ciphertext: "97687268d6ecccc0c07b25e25ecfe584b3fffd940c16a18c1b5549d2f838029e39312523a78662d5be7fcbcc98ebf5"},
+		{length: 48, ciphertext: "97687268d6ecccc0c07b25e25ecfe5849dad8bbb96c4cdc03bc103e1a194bbd839312523a78662d5be7fcbcc98ebf5a8"},
+		{length: 64, ciphertext: "97687268d6ecccc0c07b25e25ecfe58439312523a78662d5be7fcbcc98ebf5a84807efe836ee89a526730dbc2f7bc8409dad8bbb96c4cdc03bc103e1a194bbd8"},
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		panic(err)
+	}
+	iv := make([]byte, aes.BlockSize)
+
+	for _, tv := range cs3TestVectors {
+		tv := tv
+		It(fmt.Sprintf("should pass the RFC 3962 CS3 example of %d bytes", tv.length), func() {
+			mode := modes.NewCBCCS3(block)
+
+			ciphertext, err := mode.EncryptWithIV(iv, plaintext[:tv.length])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ciphertext[aes.BlockSize:]).To(Equal(decodeHex(tv.ciphertext)))
+
+			decrypted, err := mode.Decrypt(ciphertext)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decrypted).To(Equal(plaintext[:tv.length]))
+		})
+	}
+
+	It("should only differ between variants in the order of the last two blocks", func() {
+		for _, tv := range cs3TestVectors {
+			cs3 := decodeHex(tv.ciphertext)
+			n := (tv.length + aes.BlockSize - 1) / aes.BlockSize
+			d := tv.length - (n-1)*aes.BlockSize
+			head, last, prevStar := cs3[:(n-2)*aes.BlockSize], cs3[(n-2)*aes.BlockSize:(n-1)*aes.BlockSize], cs3[(n-1)*aes.BlockSize:]
+			Expect(prevStar).To(HaveLen(d))
+
+			cs1 := append(append(append([]byte{}, head...), prevStar...), last...)
+			cs2 := cs3
+			if d == aes.BlockSize {
+				cs2 = cs1
+			}
+
+			got, err := modes.NewCBCCS1(block).EncryptWithIV(iv, plaintext[:tv.length])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(got[aes.BlockSize:]).To(Equal(cs1))
+
+			got, err = modes.NewCBCCS2(block).EncryptWithIV(iv, plaintext[:tv.length])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(got[aes.BlockSize:]).To(Equal(cs2))
+		}
+	})
+
+	It("should equal CBC for whole blocks with CS1 and CS2", func() {
+		for _, length := range []int{16, 32, 64} {
+			cbc, err := modes.NewCBCNoPadding(block).EncryptWithIV(iv, plaintext[:length])
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, mode := range []modes.Mode{modes.NewCBCCS1(block), modes.NewCBCCS2(block)} {
+				got, err := mode.EncryptWithIV(iv, plaintext[:length])
+				Expect(err).NotTo(HaveOccurred())
+				Expect(got).To(Equal(cbc))
+			}
+		}
+	})
+
+	It("should keep the ciphertext as long as the plaintext and round-trip", func() {
+		for _, mode := range []modes.Mode{modes.NewCBCCS1(block), modes.NewCBCCS2(block), modes.NewCBCCS3(block)} {
+			for length := aes.BlockSize; length <= len(plaintext); length++ {
+				ciphertext, err := mode.Encrypt(plaintext[:length])
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ciphertext).To(HaveLen(aes.BlockSize + length))
+
+				decrypted, err := mode.Decrypt(ciphertext)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(decrypted).To(Equal(plaintext[:length]))
+			}
+		}
+	})
+
+	It("should reject inputs shorter than one block", func() {
+		for _, mode := range []modes.Mode{modes.NewCBCCS1(block), modes.NewCBCCS2(block), modes.NewCBCCS3(block)} {
+			_, err := mode.EncryptWithIV(iv, plaintext[:aes.BlockSize-1])
+			Expect(err).To(HaveOccurred())
+
+			_, err = mode.Decrypt(make([]byte, 2*aes.BlockSize-1))
+			Expect(err).To(HaveOccurred())
+		}
+	})
+})
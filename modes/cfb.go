@@ -0,0 +1,181 @@
+package modes
+
+import (
+	"crypto/cipher"
+	"fmt"
+)
+
+// cfbMode is cipher feedback with a configurable segment size.
+type cfbMode struct {
+	block       cipher.Block
+	segmentBits int
+}
+
+// NewCFB returns the full-block cipher feedback mode (CFB-128 for AES) of the given block cipher.
+func NewCFB(b cipher.Block) Mode {
+	return &cfbMode{block: b, segmentBits: 8 * b.BlockSize()}
+}
+
+// NewCFBWithSegmentSize returns the cipher feedback mode CFB-s of the given block cipher (NIST SP 800-38A,
+// section 6.3), where s is segmentBits: 1, or a multiple of 8 up to the block size in bits. CFB-1 and
+// CFB-8 call the block cipher once per bit and once per byte respectively.
+//
+// Smaller segments make CFB self-synchronizing at a finer grain: a corrupted, inserted or lost segment only
+// garbles the plaintext until it has been shifted out of the input block, that is for one block size worth
+// of ciphertext, and decryption then recovers by itself.
+func NewCFBWithSegmentSize(b cipher.Block, segmentBits int) (Mode, error) {
+	if err := checkSegmentSize(b, segmentBits); err != nil {
+		return nil, err
+	}
+	return &cfbMode{block: b, segmentBits: segmentBits}, nil
+}
+
+func (m *cfbMode) IVSize() int {
+	return m.block.BlockSize()
+}
+
+func (m *cfbMode) Encrypt(plaintext []byte) ([]byte, error) {
+	return encryptWithRandomIV(m, plaintext)
+}
+
+func (m *cfbMode) EncryptWithIV(iv, plaintext []byte) ([]byte, error) {
+	ciphertext, body, err := frame(iv, m.IVSize(), len(plaintext))
+	if err != nil {
+		return nil, err
+	}
+
+	newCFBStream(m.block, iv, m.segmentBits, false).XORKeyStream(body, plaintext)
+
+	return ciphertext, nil
+}
+
+func (m *cfbMode) Decrypt(ciphertext []byte) ([]byte, error) {
+	iv, body, err := unframe(ciphertext, m.IVSize())
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext := make([]byte, len(body))
+	newCFBStream(m.block, iv, m.segmentBits, true).XORKeyStream(plaintext, body)
+
+	return plaintext, nil
+}
+
+// NewCFBEncrypter returns a cipher.Stream encrypting with CFB-s, where s is segmentBits.
+// Unlike cipher.NewCFBEncrypter, it supports segments smaller than the block.
+func NewCFBEncrypter(b cipher.Block, iv []byte, segmentBits int) (cipher.Stream, error) {
+	if err := checkCFBParams(b, iv, segmentBits); err != nil {
+		return nil, err
+	}
+	return newCFBStream(b, iv, segmentBits, false), nil
+}
+
+// NewCFBDecrypter returns a cipher.Stream decrypting with CFB-s, where s is segmentBits.
+func NewCFBDecrypter(b cipher.Block, iv []byte, segmentBits int) (cipher.Stream, error) {
+	if err := checkCFBParams(b, iv, segmentBits); err != nil {
+		return nil, err
+	}
+	return newCFBStream(b, iv, segmentBits, true), nil
+}
+
+func checkSegmentSize(b cipher.Block, segmentBits int) error {
+	if segmentBits != 1 && (segmentBits <= 0 || segmentBits%8 != 0 || segmentBits > 8*b.BlockSize()) {
+		return fmt.Errorf("modes: CFB segment size must be 1 or a multiple of 8 up to %d bits, got %d", 8*b.BlockSize(), segmentBits)
+	}
+	return nil
+}
+
+func checkCFBParams(b cipher.Block, iv []byte, segmentBits int) error {
+	if len(iv) != b.BlockSize() {
+		return fmt.Errorf("modes: IV must be %d bytes, got %d", b.BlockSize(), len(iv))
+	}
+	return checkSegmentSize(b, segmentBits)
+}
+
+// cfbStream implements CFB-s as a cipher.Stream. The input block I_j is a shift register: after every
+// segment it is shifted left by s bits and the s bits of ciphertext are shifted in.
+type cfbStream struct {
+	block       cipher.Block
+	register    []byte // input block I_j
+	output      []byte // output block O_j = E(I_j)
+	segment     []byte // ciphertext of the current byte-sized segment
+	segmentBits int
+	used        int // bytes of the current segment already processed
+	decrypt     bool
+}
+
+func newCFBStream(b cipher.Block, iv []byte, segmentBits int, decrypt bool) *cfbStream {
+	x := &cfbStream{
+		block:       b,
+		register:    make([]byte, b.BlockSize()),
+		output:      make([]byte, b.BlockSize()),
+		segmentBits: segmentBits,
+		decrypt:     decrypt,
+	}
+	copy(x.register, iv)
+	if segmentBits%8 == 0 {
+		x.segment = make([]byte, segmentBits/8)
+	}
+	return x
+}
+
+func (x *cfbStream) XORKeyStream(dst, src []byte) {
+	if len(dst) < len(src) {
+		panic("crypto/cipher: output smaller than input")
+	}
+
+	for i, in := range src {
+		if x.segmentBits == 1 {
+			dst[i] = x.cryptBits(in)
+		} else {
+			dst[i] = x.cryptByte(in)
+		}
+	}
+}
+
+// cryptByte processes one byte of a segment of whole bytes. Segments may be split across XORKeyStream calls.
+func (x *cfbStream) cryptByte(in byte) byte {
+	if x.used == 0 {
+		x.block.Encrypt(x.output, x.register)
+	}
+
+	out := in ^ x.output[x.used]
+	ciphertext := out
+	if x.decrypt {
+		ciphertext = in
+	}
+	x.segment[x.used] = ciphertext
+	x.used++
+
+	// The segment is complete: shift it into the input block
+	if x.used == len(x.segment) {
+		copy(x.register, x.register[len(x.segment):])
+		copy(x.register[len(x.register)-len(x.segment):], x.segment)
+		x.used = 0
+	}
+
+	return out
+}
+
+// cryptBits processes the 8 one-bit segments of a byte, most significant bit first.
+func (x *cfbStream) cryptBits(in byte) byte {
+	var out byte
+	for bit := 7; bit >= 0; bit-- {
+		x.block.Encrypt(x.output, x.register)
+
+		inBit := in >> bit & 1
+		outBit := inBit ^ x.output[0]>>7
+		ciphertextBit := outBit
+		if x.decrypt {
+			ciphertextBit = inBit
+		}
+		out |= outBit << bit
+
+		// Shift the input block left by one bit and append the ciphertext bit
+		for j := 0; j < len(x.register)-1; j++ {
+			x.register[j] = x.register[j]<<1 | x.register[j+1]>>7
+		}
+		x.register[len(x.register)-1] = x.register[len(x.register)-1]<<1 | ciphertextBit
+	}
+	return out
+}
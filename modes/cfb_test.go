@@ -0,0 +1,162 @@
+package modes_test
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"fmt"
+
+	"github.com/japananh/crypto/modes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("modes - cfb segment sizes", func() {
+	var (
+		block     cipher.Block
+		iv        []byte
+		plaintext []byte
+	)
+
+	BeforeEach(func() {
+		var err error
+		block, err = aes.NewCipher([]byte("YELLOW SUBMARINE"))
+		Expect(err).NotTo(HaveOccurred())
+
+		iv = bytes.Repeat([]byte{0x42}, aes.BlockSize)
+		plaintext = []byte("Serial-line devices talk CFB-8, one byte at a time, and resynchronize on their own.")
+	})
+
+	for _, segmentBits := range []int{1, 8, 64, 128} {
+		segmentBits := segmentBits
+
+		It(fmt.Sprintf("should round-trip with CFB-%d", segmentBits), func() {
+			mode, err := modes.NewCFBWithSegmentSize(block, segmentBits)
+			Expect(err).NotTo(HaveOccurred())
+
+			ciphertext, err := mode.EncryptWithIV(iv, plaintext)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ciphertext).To(HaveLen(aes.BlockSize + len(plaintext)))
+
+			decrypted, err := mode.Decrypt(ciphertext)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decrypted).To(Equal(plaintext))
+		})
+
+		It(fmt.Sprintf("should give the same result when CFB-%d is fed in pieces", segmentBits), func() {
+			whole := make([]byte, len(plaintext))
+			encrypter, err := modes.NewCFBEncrypter(block, iv, segmentBits)
+			Expect(err).NotTo(HaveOccurred())
+			encrypter.XORKeyStream(whole, plaintext)
+
+			pieces := make([]byte, len(plaintext))
+			encrypter, err = modes.NewCFBEncrypter(block, iv, segmentBits)
+			Expect(err).NotTo(HaveOccurred())
+			for start, size := 0, 1; start < len(plaintext); start, size = start+size, size+2 {
+				end := start + size
+				if end > len(plaintext) {
+					end = len(plaintext)
+				}
+				encrypter.XORKeyStream(pieces[start:end], plaintext[start:end])
+			}
+			Expect(pieces).To(Equal(whole))
+
+			decrypted := make([]byte, len(whole))
+			decrypter, err := modes.NewCFBDecrypter(block, iv, segmentBits)
+			Expect(err).NotTo(HaveOccurred())
+			decrypter.XORKeyStream(decrypted[:5], whole[:5])
+			decrypter.XORKeyStream(decrypted[5:], whole[5:])
+			Expect(decrypted).To(Equal(plaintext))
+		})
+	}
+
+	It("should match the definition of CFB-64", func() {
+		mode, err := modes.NewCFBWithSegmentSize(block, 64)
+		Expect(err).NotTo(HaveOccurred())
+		ciphertext, err := mode.EncryptWithIV(iv, plaintext[:16])
+		Expect(err).NotTo(HaveOccurred())
+
+		// C_1 = P_1 xor MSB_64(E(IV)), C_2 = P_2 xor MSB_64(E(LSB_64(IV) || C_1))
+		output := make([]byte, aes.BlockSize)
+		block.Encrypt(output, iv)
+		want := make([]byte, 16)
+		subtle.XORBytes(want[:8], plaintext[:8], output[:8])
+
+		block.Encrypt(output, append(append([]byte{}, iv[8:]...), want[:8]...))
+		subtle.XORBytes(want[8:], plaintext[8:16], output[:8])
+
+		Expect(ciphertext[aes.BlockSize:]).To(Equal(want))
+	})
+
+	It("should equal the standard library CFB with full-block segments", func() {
+		mode, err := modes.NewCFBWithSegmentSize(block, 128)
+		Expect(err).NotTo(HaveOccurred())
+		ciphertext, err := mode.EncryptWithIV(iv, plaintext)
+		Expect(err).NotTo(HaveOccurred())
+
+		want := make([]byte, len(plaintext))
+		cipher.NewCFBEncrypter(block, iv).XORKeyStream(want, plaintext)
+		Expect(ciphertext[aes.BlockSize:]).To(Equal(want))
+	})
+
+	It("should recover from a corrupted byte after one block with CFB-8", func() {
+		mode, err := modes.NewCFBWithSegmentSize(block, 8)
+		Expect(err).NotTo(HaveOccurred())
+		ciphertext, err := mode.EncryptWithIV(iv, plaintext)
+		Expect(err).NotTo(HaveOccurred())
+
+		// Corrupt ciphertext byte 10: it garbles plaintext byte 10, then the following 16 bytes
+		// while it is in the shift register
+		ciphertext[aes.BlockSize+10] ^= 0x01
+		decrypted, err := mode.Decrypt(ciphertext)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(decrypted[:10]).To(Equal(plaintext[:10]))
+		Expect(decrypted[10]).To(Equal(plaintext[10] ^ 0x01))
+		Expect(decrypted[11 : 11+aes.BlockSize]).NotTo(Equal(plaintext[11 : 11+aes.BlockSize]))
+		Expect(decrypted[11+aes.BlockSize:]).To(Equal(plaintext[11+aes.BlockSize:]))
+	})
+
+	It("should resynchronize after a lost byte with CFB-8", func() {
+		mode, err := modes.NewCFBWithSegmentSize(block, 8)
+		Expect(err).NotTo(HaveOccurred())
+		ciphertext, err := mode.EncryptWithIV(iv, plaintext)
+		Expect(err).NotTo(HaveOccurred())
+
+		// Drop ciphertext byte 10, as a noisy serial line might
+		lost := append(append([]byte{}, ciphertext[:aes.BlockSize+10]...), ciphertext[aes.BlockSize+11:]...)
+		decrypted, err := mode.Decrypt(lost)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(decrypted[:10]).To(Equal(plaintext[:10]))
+		Expect(decrypted[10+aes.BlockSize:]).To(Equal(plaintext[11+aes.BlockSize:]))
+	})
+
+	It("should recover from a flipped bit after one block with CFB-1", func() {
+		mode, err := modes.NewCFBWithSegmentSize(block, 1)
+		Expect(err).NotTo(HaveOccurred())
+		ciphertext, err := mode.EncryptWithIV(iv, plaintext)
+		Expect(err).NotTo(HaveOccurred())
+
+		// The flipped bit stays in the 128-bit shift register for the next 128 bits
+		ciphertext[aes.BlockSize+10] ^= 0x80
+		decrypted, err := mode.Decrypt(ciphertext)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(decrypted[:10]).To(Equal(plaintext[:10]))
+		Expect(decrypted[10] & 0x80).To(Equal(plaintext[10]&0x80 ^ 0x80))
+		Expect(decrypted[11+aes.BlockSize:]).To(Equal(plaintext[11+aes.BlockSize:]))
+	})
+
+	It("should reject unsupported segment sizes", func() {
+		for _, segmentBits := range []int{0, 2, 7, 12, 136, -8} {
+			_, err := modes.NewCFBWithSegmentSize(block, segmentBits)
+			Expect(err).To(HaveOccurred())
+		}
+
+		_, err := modes.NewCFBEncrypter(block, iv[:8], 8)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,221 @@
+package modes
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// File is the storage underneath a CTRFile, such as an *os.File.
+type File interface {
+	io.ReaderAt
+	io.WriterAt
+	io.Seeker
+}
+
+// CTRFile gives random access to a file encrypted with CTR mode. The file is framed like the ciphertexts
+// of NewCTR: the initial counter block comes first, followed by the encrypted data.
+//
+// Because the keystream block for any offset can be computed directly from the initial counter block,
+// any part of the file can be read or overwritten without processing what comes before it.
+// Offsets are positions in the plaintext, that is after the counter block.
+//
+// Overwriting data reuses the keystream of the old data, so anyone who sees both versions of the file learns
+// the XOR of the old and new plaintext. Writing past the end of the file leaves a gap that does not decrypt
+// to zeros.
+type CTRFile struct {
+	file   File
+	block  cipher.Block
+	iv     []byte
+	offset int64
+}
+
+var errNegativeOffset = errors.New("modes: negative offset")
+
+// CreateCTRFile writes a fresh random initial counter block at the start of f and returns a CTRFile
+// for writing data after it.
+func CreateCTRFile(b cipher.Block, f File) (*CTRFile, error) {
+	iv, err := GenerateIV(b.BlockSize())
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.WriteAt(iv, 0); err != nil {
+		return nil, err
+	}
+
+	return &CTRFile{file: f, block: b, iv: iv}, nil
+}
+
+// OpenCTRFile reads the initial counter block from the start of f and returns a CTRFile for the data after it.
+func OpenCTRFile(b cipher.Block, f File) (*CTRFile, error) {
+	iv := make([]byte, b.BlockSize())
+	// ReaderAt may report io.EOF together with a complete read of the counter block, for a file without data
+	if n, err := f.ReadAt(iv, 0); err != nil && !(err == io.EOF && n == len(iv)) {
+		if err == io.EOF {
+			return nil, ErrCiphertextTooShort
+		}
+		return nil, err
+	}
+
+	return &CTRFile{file: f, block: b, iv: iv}, nil
+}
+
+// ReadAt decrypts len(p) bytes starting at plaintext offset off.
+func (c *CTRFile) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errNegativeOffset
+	}
+
+	n, err := c.file.ReadAt(p, c.headerSize()+off)
+	c.xorKeyStreamAt(p[:n], p[:n], off)
+
+	return n, err
+}
+
+// WriteAt encrypts p and writes it at plaintext offset off. p is not modified.
+func (c *CTRFile) WriteAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errNegativeOffset
+	}
+
+	encrypted := make([]byte, len(p))
+	c.xorKeyStreamAt(encrypted, p, off)
+
+	return c.file.WriteAt(encrypted, c.headerSize()+off)
+}
+
+// Read decrypts up to len(p) bytes at the current offset and advances it.
+func (c *CTRFile) Read(p []byte) (int, error) {
+	n, err := c.ReadAt(p, c.offset)
+	c.offset += int64(n)
+
+	// ReaderAt reports a short read as io.EOF; for a Reader that is only the end once nothing was read
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+// Write encrypts p, writes it at the current offset and advances it.
+func (c *CTRFile) Write(p []byte) (int, error) {
+	n, err := c.WriteAt(p, c.offset)
+	c.offset += int64(n)
+
+	return n, err
+}
+
+// Seek sets the plaintext offset for the next Read or Write, as described by io.Seeker.
+func (c *CTRFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += c.offset
+	case io.SeekEnd:
+		size, err := c.Size()
+		if err != nil {
+			return 0, err
+		}
+		offset += size
+	default:
+		return 0, fmt.Errorf("modes: invalid whence %d", whence)
+	}
+
+	if offset < 0 {
+		return 0, errNegativeOffset
+	}
+	c.offset = offset
+
+	return offset, nil
+}
+
+// Size returns the length of the plaintext, the size of the file without the initial counter block.
+// The offset of the underlying file is left unchanged.
+func (c *CTRFile) Size() (int64, error) {
+	end, err := fileSize(c.file)
+	if err != nil {
+		return 0, err
+	}
+	return max64(end-c.headerSize(), 0), nil
+}
+
+// fileSize returns the size of f, from Stat when f has it, such as an *os.File. Otherwise it seeks to the end
+// and back, so callers that Read or Write f directly keep their offset.
+func fileSize(f File) (int64, error) {
+	if s, ok := f.(interface{ Stat() (os.FileInfo, error) }); ok {
+		info, err := s.Stat()
+		if err != nil {
+			return 0, err
+		}
+		return info.Size(), nil
+	}
+
+	offset, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+	end, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+	return end, nil
+}
+
+func (c *CTRFile) headerSize() int64 {
+	return int64(len(c.iv))
+}
+
+// xorKeyStreamAt XORs src with the keystream starting at plaintext offset off.
+func (c *CTRFile) xorKeyStreamAt(dst, src []byte, off int64) {
+	blockSize := int64(c.block.BlockSize())
+	counter := c.counterAt(uint64(off / blockSize))
+	skip := int(off % blockSize)
+
+	keystream := make([]byte, blockSize)
+	for len(src) > 0 {
+		c.block.Encrypt(keystream, counter)
+		n := subtle.XORBytes(dst, src, keystream[skip:])
+		dst, src = dst[n:], src[n:]
+
+		skip = 0
+		incrementCounter(counter)
+	}
+}
+
+// counterAt returns the counter block of block index i: the initial counter block plus i,
+// as a big-endian integer the size of the block like cipher.NewCTR.
+func (c *CTRFile) counterAt(i uint64) []byte {
+	counter := make([]byte, len(c.iv))
+	copy(counter, c.iv)
+
+	carry := i
+	for j := len(counter) - 1; j >= 0 && carry > 0; j-- {
+		sum := uint64(counter[j]) + carry&0xff
+		counter[j] = byte(sum)
+		carry = carry>>8 + sum>>8
+	}
+
+	return counter
+}
+
+// incrementCounter adds one to a big-endian counter block.
+func incrementCounter(counter []byte) {
+	for j := len(counter) - 1; j >= 0; j-- {
+		counter[j]++
+		if counter[j] != 0 {
+			break
+		}
+	}
+}
+
+func max64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,241 @@
+package modes_test
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/japananh/crypto/modes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("modes - ctr file", func() {
+	var (
+		block     cipher.Block
+		file      *os.File
+		plaintext []byte
+	)
+
+	BeforeEach(func() {
+		var err error
+		block, err = aes.NewCipher([]byte("YELLOW SUBMARINE"))
+		Expect(err).NotTo(HaveOccurred())
+
+		file, err = os.Create(filepath.Join(GinkgoT().TempDir(), "data.enc"))
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(file.Close)
+
+		plaintext = make([]byte, 100)
+		for i := range plaintext {
+			plaintext[i] = byte(i)
+		}
+	})
+
+	// create writes the plaintext to a new CTRFile
+	create := func() *modes.CTRFile {
+		ctrFile, err := modes.CreateCTRFile(block, file)
+		Expect(err).NotTo(HaveOccurred())
+
+		n, err := ctrFile.Write(plaintext)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(len(plaintext)))
+
+		return ctrFile
+	}
+
+	It("should write the same framing as the CTR mode", func() {
+		create()
+
+		raw, err := os.ReadFile(file.Name())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(raw).To(HaveLen(aes.BlockSize + len(plaintext)))
+
+		decrypted, err := modes.NewCTR(block).Decrypt(raw)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(decrypted).To(Equal(plaintext))
+	})
+
+	It("should read any range at unaligned offsets", func() {
+		create()
+
+		ctrFile, err := modes.OpenCTRFile(block, file)
+		Expect(err).NotTo(HaveOccurred())
+
+		for off := 0; off < len(plaintext); off++ {
+			for _, length := range []int{1, 3, 15, 16, 17, 33} {
+				end := off + length
+				if end > len(plaintext) {
+					end = len(plaintext)
+				}
+
+				buf := make([]byte, length)
+				n, err := ctrFile.ReadAt(buf, int64(off))
+				Expect(n).To(Equal(end - off))
+				if end-off < length {
+					Expect(err).To(Equal(io.EOF))
+				} else {
+					Expect(err).NotTo(HaveOccurred())
+				}
+				Expect(buf[:n]).To(Equal(plaintext[off:end]))
+			}
+		}
+	})
+
+	It("should patch writes that span block boundaries", func() {
+		ctrFile := create()
+
+		patches := []struct {
+			offset int64
+			data   []byte
+		}{
+			{offset: 13, data: []byte("spans the first two blocks")},
+			{offset: 31, data: []byte("x")},
+			{offset: 47, data: bytes.Repeat([]byte{0xaa}, 34)},
+			{offset: 96, data: []byte("grows the file")},
+		}
+
+		want := append([]byte{}, plaintext...)
+		for _, patch := range patches {
+			n, err := ctrFile.WriteAt(patch.data, patch.offset)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(n).To(Equal(len(patch.data)))
+
+			if end := int(patch.offset) + len(patch.data); end > len(want) {
+				want = append(want, make([]byte, end-len(want))...)
+			}
+			copy(want[patch.offset:], patch.data)
+		}
+
+		raw, err := os.ReadFile(file.Name())
+		Expect(err).NotTo(HaveOccurred())
+		decrypted, err := modes.NewCTR(block).Decrypt(raw)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(decrypted).To(Equal(want))
+	})
+
+	It("should read, write and seek like a file", func() {
+		ctrFile := create()
+
+		size, err := ctrFile.Size()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(size).To(Equal(int64(len(plaintext))))
+
+		offset, err := ctrFile.Seek(-10, io.SeekEnd)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(offset).To(Equal(int64(90)))
+
+		rest, err := io.ReadAll(ctrFile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rest).To(Equal(plaintext[90:]))
+
+		_, err = ctrFile.Seek(20, io.SeekStart)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ctrFile.Write([]byte("patched"))
+		Expect(err).NotTo(HaveOccurred())
+
+		offset, err = ctrFile.Seek(-7, io.SeekCurrent)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(offset).To(Equal(int64(20)))
+
+		buf := make([]byte, 9)
+		_, err = io.ReadFull(ctrFile, buf)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(buf).To(Equal(append([]byte("patched"), plaintext[27:29]...)))
+
+		_, err = ctrFile.Seek(-1, io.SeekStart)
+		Expect(err).To(HaveOccurred())
+		_, err = ctrFile.ReadAt(buf, -1)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should carry the counter into the upper bytes of the counter block", func() {
+		// An initial counter block ending in 0xff..ff makes block 1 carry into byte 7
+		iv := append(bytes.Repeat([]byte{0x01}, 8), bytes.Repeat([]byte{0xff}, 8)...)
+		_, err := file.WriteAt(append(iv, make([]byte, 64)...), 0)
+		Expect(err).NotTo(HaveOccurred())
+
+		ctrFile, err := modes.OpenCTRFile(block, file)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ctrFile.WriteAt(plaintext[:64], 0)
+		Expect(err).NotTo(HaveOccurred())
+
+		raw, err := os.ReadFile(file.Name())
+		Expect(err).NotTo(HaveOccurred())
+		want := make([]byte, 64)
+		cipher.NewCTR(block, iv).XORKeyStream(want, plaintext[:64])
+		Expect(raw[aes.BlockSize:]).To(Equal(want))
+	})
+
+	It("should open a file with only a counter block when ReadAt reports io.EOF with a full read", func() {
+		iv := bytes.Repeat([]byte{0x01}, aes.BlockSize)
+		_, err := file.WriteAt(iv, 0)
+		Expect(err).NotTo(HaveOccurred())
+
+		ctrFile, err := modes.OpenCTRFile(block, eofAtEndFile{file})
+		Expect(err).NotTo(HaveOccurred())
+
+		size, err := ctrFile.Size()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(size).To(BeZero())
+	})
+
+	It("should leave the offset of the underlying file unchanged when getting the size", func() {
+		create()
+
+		for _, f := range []modes.File{file, withoutStat{file}} {
+			_, err := file.Seek(5, io.SeekStart)
+			Expect(err).NotTo(HaveOccurred())
+
+			ctrFile, err := modes.OpenCTRFile(block, f)
+			Expect(err).NotTo(HaveOccurred())
+			size, err := ctrFile.Size()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(size).To(Equal(int64(len(plaintext))))
+
+			offset, err := file.Seek(0, io.SeekCurrent)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(offset).To(Equal(int64(5)))
+		}
+	})
+
+	It("should reject files without a counter block", func() {
+		_, err := modes.OpenCTRFile(block, file)
+		Expect(err).To(MatchError(modes.ErrCiphertextTooShort))
+	})
+})
+
+// eofAtEndFile reports io.EOF with every read that reaches the end of the file, even a complete one,
+// as the io.ReaderAt contract allows.
+type eofAtEndFile struct {
+	*os.File
+}
+
+func (f eofAtEndFile) ReadAt(p []byte, off int64) (int, error) {
+	n, err := f.File.ReadAt(p, off)
+	if err != nil {
+		return n, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return n, err
+	}
+	if off+int64(n) == info.Size() {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// withoutStat hides the Stat method of a file.
+type withoutStat struct {
+	f *os.File
+}
+
+func (w withoutStat) ReadAt(p []byte, off int64) (int, error)      { return w.f.ReadAt(p, off) }
+func (w withoutStat) WriteAt(p []byte, off int64) (int, error)     { return w.f.WriteAt(p, off) }
+func (w withoutStat) Seek(offset int64, whence int) (int64, error) { return w.f.Seek(offset, whence) }
@@ -0,0 +1,223 @@
+package modes
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// ErrIVReused is returned when an IV is about to be used a second time with the same key.
+// For CTR, OFB and CFB that would encrypt two messages with the same keystream: the XOR of the two
+// ciphertexts is the XOR of the two plaintexts, a many-time pad.
+var ErrIVReused = errors.New("modes: IV reused with the same key")
+
+// ivRecordSize is the size of a remembered (key fingerprint, IV) pair: SHA-256(fingerprint || IV).
+const ivRecordSize = sha256.Size
+
+// IVGuard remembers the (key, IV) pairs used for encryption and rejects reuse. It only remembers the most
+// recent pairs up to its capacity, so it catches the common bugs of constant or repeating IVs, not reuse
+// across arbitrarily long histories. It is safe for concurrent use.
+//
+// Only an IV equal to an earlier one is detected. With CTR, two different initial counter blocks can still
+// cover overlapping counter ranges, for example IV and IV+1 with messages longer than a block, and reuse part
+// of the keystream without being caught. Random IVs make that overlap negligible.
+type IVGuard struct {
+	mu       sync.Mutex
+	capacity int
+	seen     map[[ivRecordSize]byte]struct{}
+	order    [][ivRecordSize]byte // records in insertion order, oldest first
+
+	// file persists the records when the guard is backed by a file
+	file    *os.File
+	records int // number of records in file
+}
+
+// NewMemoryIVGuard returns an IVGuard that remembers up to capacity pairs in memory.
+func NewMemoryIVGuard(capacity int) *IVGuard {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &IVGuard{capacity: capacity, seen: make(map[[ivRecordSize]byte]struct{})}
+}
+
+// NewFileIVGuard returns an IVGuard that remembers up to capacity pairs in the file at path, so reuse is also
+// detected across restarts. The file only holds hashes of the pairs. It is created if it does not exist.
+func NewFileIVGuard(path string, capacity int) (*IVGuard, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if len(data)%ivRecordSize != 0 {
+		file.Close()
+		return nil, fmt.Errorf("modes: IV guard file %s is corrupted", path)
+	}
+
+	g := NewMemoryIVGuard(capacity)
+	g.records = len(data) / ivRecordSize
+	for len(data) > 0 {
+		var record [ivRecordSize]byte
+		copy(record[:], data)
+		g.remember(record)
+		data = data[ivRecordSize:]
+	}
+	g.file = file
+
+	// Drop the records that no longer fit
+	if err := g.compact(); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return g, nil
+}
+
+// Check records that iv is used with the key of b and returns ErrIVReused if exactly that pair was already recorded.
+func (g *IVGuard) Check(b cipher.Block, iv []byte) error {
+	fingerprint := KeyFingerprint(b)
+
+	h := sha256.New()
+	h.Write(fingerprint)
+	h.Write(iv)
+	var record [ivRecordSize]byte
+	h.Sum(record[:0])
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, ok := g.seen[record]; ok {
+		return ErrIVReused
+	}
+	g.remember(record)
+
+	return g.persist(record)
+}
+
+// Close closes the file backing the guard, if any.
+func (g *IVGuard) Close() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.file == nil {
+		return nil
+	}
+	err := g.file.Close()
+	g.file = nil
+	return err
+}
+
+// remember adds a record, evicting the oldest one when the guard is full.
+func (g *IVGuard) remember(record [ivRecordSize]byte) {
+	if _, ok := g.seen[record]; ok {
+		return
+	}
+	if len(g.order) == g.capacity {
+		delete(g.seen, g.order[0])
+		g.order = g.order[1:]
+	}
+	g.seen[record] = struct{}{}
+	g.order = append(g.order, record)
+}
+
+// persist appends a record to the file, and rewrites the file once it holds twice as many records as needed.
+func (g *IVGuard) persist(record [ivRecordSize]byte) error {
+	if g.file == nil {
+		return nil
+	}
+
+	if _, err := g.file.WriteAt(record[:], int64(g.records)*ivRecordSize); err != nil {
+		return err
+	}
+	g.records++
+
+	if g.records >= 2*g.capacity {
+		return g.compact()
+	}
+	return nil
+}
+
+// compact rewrites the file with the records the guard still remembers.
+func (g *IVGuard) compact() error {
+	if g.records == len(g.order) {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	for _, record := range g.order {
+		buf.Write(record[:])
+	}
+	if err := g.file.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := g.file.WriteAt(buf.Bytes(), 0); err != nil {
+		return err
+	}
+	g.records = len(g.order)
+
+	return nil
+}
+
+// fingerprintInput is the block encrypted to fingerprint a key. It must not be the all-zero block,
+// whose encryption is the secret GHASH key when the same key is used with GCM.
+var fingerprintInput = sha256.Sum256([]byte("modes: key fingerprint"))
+
+// KeyFingerprint identifies the key of b without revealing it: the SHA-256 hash of the encryption of a fixed
+// block, truncated to 16 bytes.
+func KeyFingerprint(b cipher.Block) []byte {
+	in := make([]byte, b.BlockSize())
+	copy(in, fingerprintInput[:])
+
+	out := make([]byte, b.BlockSize())
+	b.Encrypt(out, in)
+
+	sum := sha256.Sum256(out)
+	return sum[:16]
+}
+
+// guardedMode consults an IVGuard before every encryption.
+type guardedMode struct {
+	Mode
+	block cipher.Block
+	guard *IVGuard
+}
+
+// blockMode is implemented by the modes of this package, to find the block cipher a Mode was created with.
+type blockMode interface {
+	cipherBlock() cipher.Block
+}
+
+func (m *streamMode) cipherBlock() cipher.Block { return m.block }
+func (m *cbcMode) cipherBlock() cipher.Block    { return m.block }
+func (m *cbcCSMode) cipherBlock() cipher.Block  { return m.block }
+func (m *cfbMode) cipherBlock() cipher.Block    { return m.block }
+
+// WithIVGuard returns a Mode that checks every IV against guard before encrypting with m, and fails with
+// ErrIVReused instead of reusing an IV with the same key. m must be a Mode created by this package.
+func WithIVGuard(m Mode, guard *IVGuard) (Mode, error) {
+	bm, ok := m.(blockMode)
+	if !ok {
+		return nil, fmt.Errorf("modes: cannot guard %T", m)
+	}
+	return &guardedMode{Mode: m, block: bm.cipherBlock(), guard: guard}, nil
+}
+
+func (m *guardedMode) Encrypt(plaintext []byte) ([]byte, error) {
+	return encryptWithRandomIV(m, plaintext)
+}
+
+func (m *guardedMode) EncryptWithIV(iv, plaintext []byte) ([]byte, error) {
+	if err := m.guard.Check(m.block, iv); err != nil {
+		return nil, err
+	}
+	return m.Mode.EncryptWithIV(iv, plaintext)
+}
@@ -0,0 +1,146 @@
+package modes_test
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"os"
+	"path/filepath"
+
+	"github.com/japananh/crypto/modes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("modes - iv guard", func() {
+	var block, otherBlock cipher.Block
+
+	BeforeEach(func() {
+		var err error
+		block, err = aes.NewCipher([]byte("YELLOW SUBMARINE"))
+		Expect(err).NotTo(HaveOccurred())
+		otherBlock, err = aes.NewCipher([]byte("ORANGE SUBMARINE"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	iv := func(i byte) []byte {
+		iv := make([]byte, aes.BlockSize)
+		iv[0] = i
+		return iv
+	}
+
+	It("should reject an IV reused with the same key", func() {
+		guard := modes.NewMemoryIVGuard(10)
+
+		Expect(guard.Check(block, iv(1))).To(Succeed())
+		Expect(guard.Check(block, iv(2))).To(Succeed())
+		Expect(guard.Check(otherBlock, iv(1))).To(Succeed())
+		Expect(guard.Check(block, iv(1))).To(MatchError(modes.ErrIVReused))
+	})
+
+	It("should only remember the most recent pairs", func() {
+		guard := modes.NewMemoryIVGuard(2)
+
+		Expect(guard.Check(block, iv(1))).To(Succeed())
+		Expect(guard.Check(block, iv(2))).To(Succeed())
+		Expect(guard.Check(block, iv(3))).To(Succeed())
+
+		Expect(guard.Check(block, iv(1))).To(Succeed())
+		Expect(guard.Check(block, iv(3))).To(MatchError(modes.ErrIVReused))
+	})
+
+	It("should remember pairs across restarts with a file", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "ivs")
+
+		guard, err := modes.NewFileIVGuard(path, 3)
+		Expect(err).NotTo(HaveOccurred())
+		for i := byte(1); i <= 10; i++ {
+			Expect(guard.Check(block, iv(i))).To(Succeed())
+		}
+		Expect(guard.Close()).To(Succeed())
+
+		// The file is compacted, so it never grows much beyond the capacity
+		info, err := os.Stat(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Size()).To(BeNumerically("<", 6*32))
+
+		guard, err = modes.NewFileIVGuard(path, 3)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(guard.Close)
+
+		Expect(guard.Check(block, iv(10))).To(MatchError(modes.ErrIVReused))
+		Expect(guard.Check(block, iv(8))).To(MatchError(modes.ErrIVReused))
+		Expect(guard.Check(block, iv(1))).To(Succeed())
+	})
+
+	It("should keep remembering pairs when reopened with a smaller capacity", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "ivs")
+
+		guard, err := modes.NewFileIVGuard(path, 10)
+		Expect(err).NotTo(HaveOccurred())
+		for i := byte(1); i <= 5; i++ {
+			Expect(guard.Check(block, iv(i))).To(Succeed())
+		}
+		Expect(guard.Close()).To(Succeed())
+
+		// Reopening drops the records that no longer fit from the file
+		guard, err = modes.NewFileIVGuard(path, 2)
+		Expect(err).NotTo(HaveOccurred())
+		info, err := os.Stat(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Size()).To(Equal(int64(2 * 32)))
+
+		Expect(guard.Check(block, iv(6))).To(Succeed())
+		Expect(guard.Close()).To(Succeed())
+
+		guard, err = modes.NewFileIVGuard(path, 2)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(guard.Close)
+
+		Expect(guard.Check(block, iv(6))).To(MatchError(modes.ErrIVReused))
+		Expect(guard.Check(block, iv(5))).To(MatchError(modes.ErrIVReused))
+	})
+
+	It("should reject a corrupted file", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "ivs")
+		Expect(os.WriteFile(path, []byte("not a record"), 0o600)).To(Succeed())
+
+		_, err := modes.NewFileIVGuard(path, 3)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should fingerprint keys without the encryption of the zero block", func() {
+		zero := make([]byte, aes.BlockSize)
+		block.Encrypt(zero, zero)
+
+		Expect(modes.KeyFingerprint(block)).To(HaveLen(16))
+		Expect(modes.KeyFingerprint(block)).To(Equal(modes.KeyFingerprint(block)))
+		Expect(modes.KeyFingerprint(block)).NotTo(Equal(modes.KeyFingerprint(otherBlock)))
+		Expect(modes.KeyFingerprint(block)).NotTo(Equal(zero))
+	})
+
+	It("should guard the modes of this package", func() {
+		for _, newMode := range []func(cipher.Block) modes.Mode{modes.NewCTR, modes.NewOFB, modes.NewCFB, modes.NewCBC} {
+			m, err := modes.WithIVGuard(newMode(block), modes.NewMemoryIVGuard(10))
+			Expect(err).NotTo(HaveOccurred())
+
+			ciphertext, err := m.EncryptWithIV(iv(1), []byte("guarded message"))
+			Expect(err).NotTo(HaveOccurred())
+
+			decrypted, err := m.Decrypt(ciphertext)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decrypted).To(Equal([]byte("guarded message")))
+
+			_, err = m.EncryptWithIV(iv(1), []byte("another message"))
+			Expect(err).To(MatchError(modes.ErrIVReused))
+
+			_, err = m.Encrypt([]byte("random IV"))
+			Expect(err).NotTo(HaveOccurred())
+		}
+	})
+
+	It("should refuse to guard a mode it cannot fingerprint", func() {
+		_, err := modes.WithIVGuard(nil, modes.NewMemoryIVGuard(10))
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,76 @@
+// Package modes implements block cipher modes of operation behind a single Mode interface.
+//
+// Every mode frames its ciphertext the same way: the IV (or initial counter block) comes first,
+// followed by the encrypted message, so a ciphertext can be decrypted with nothing but the key.
+package modes
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// ErrCiphertextTooShort is returned when a ciphertext is too short to hold the IV.
+var ErrCiphertextTooShort = errors.New("modes: ciphertext too short")
+
+// Mode encrypts and decrypts whole messages with a block cipher mode of operation.
+type Mode interface {
+	// IVSize returns the size of the IV that prefixes every ciphertext.
+	IVSize() int
+	// Encrypt encrypts plaintext under a fresh random IV and returns IV || ciphertext.
+	Encrypt(plaintext []byte) ([]byte, error)
+	// EncryptWithIV is like Encrypt, but uses the given IV. An IV must never be reused with the same key.
+	EncryptWithIV(iv, plaintext []byte) ([]byte, error)
+	// Decrypt decrypts IV || ciphertext as produced by Encrypt.
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// GenerateAESKey generates an AES key, either 16, 24, or 32 bytes to select AES-128, AES-192, or AES-256.
+func GenerateAESKey(size int) ([]byte, error) {
+	if size != 16 && size != 24 && size != 32 {
+		return nil, fmt.Errorf("AES key size must be 16, 24 or 32 bytes")
+	}
+	key := make([]byte, size)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// GenerateIV generates a random IV (Initialization Vector). The IV must match the block size of the cipher used.
+func GenerateIV(size int) ([]byte, error) {
+	iv := make([]byte, size)
+	if _, err := rand.Read(iv); err != nil {
+		return nil, err
+	}
+	return iv, nil
+}
+
+// encryptWithRandomIV implements Mode.Encrypt on top of Mode.EncryptWithIV.
+func encryptWithRandomIV(m Mode, plaintext []byte) ([]byte, error) {
+	iv, err := GenerateIV(m.IVSize())
+	if err != nil {
+		return nil, err
+	}
+	return m.EncryptWithIV(iv, plaintext)
+}
+
+// frame allocates IV || body for a body of the given length and returns it with the body slice.
+func frame(iv []byte, ivSize, bodyLen int) (ciphertext, body []byte, err error) {
+	if len(iv) != ivSize {
+		return nil, nil, fmt.Errorf("modes: IV must be %d bytes, got %d", ivSize, len(iv))
+	}
+
+	ciphertext = make([]byte, ivSize+bodyLen)
+	copy(ciphertext, iv)
+
+	return ciphertext, ciphertext[ivSize:], nil
+}
+
+// unframe splits a ciphertext into its IV and body.
+func unframe(ciphertext []byte, ivSize int) (iv, body []byte, err error) {
+	if len(ciphertext) < ivSize {
+		return nil, nil, ErrCiphertextTooShort
+	}
+	return ciphertext[:ivSize], ciphertext[ivSize:], nil
+}
@@ -0,0 +1,13 @@
+package modes_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestModes(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Modes Suit")
+}
@@ -0,0 +1,212 @@
+package modes_test
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"fmt"
+	"path/filepath"
+
+	"github.com/japananh/crypto/cavp"
+	"github.com/japananh/crypto/modes"
+	"github.com/japananh/crypto/padding"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("modes", func() {
+	newModes := map[string]func(cipher.Block) modes.Mode{
+		"CBC": modes.NewCBC,
+		"CTR": modes.NewCTR,
+		"CFB": modes.NewCFB,
+		"OFB": modes.NewOFB,
+	}
+
+	newBlock := func(key []byte) cipher.Block {
+		block, err := aes.NewCipher(key)
+		Expect(err).NotTo(HaveOccurred())
+		return block
+	}
+
+	// checkKnownAnswer runs a CAVP test case through EncryptWithIV or Decrypt, framing the vector's IV
+	// in front of the ciphertext.
+	checkKnownAnswer := func(newMode func(cipher.Block) modes.Mode) func(cavp.TestCase) error {
+		return func(tc cavp.TestCase) error {
+			key, err := tc.Hex("KEY")
+			if err != nil {
+				return err
+			}
+			iv, err := tc.Hex("IV")
+			if err != nil {
+				return err
+			}
+			plaintext, err := tc.Hex("PLAINTEXT")
+			if err != nil {
+				return err
+			}
+			ciphertext, err := tc.Hex("CIPHERTEXT")
+			if err != nil {
+				return err
+			}
+
+			mode := newMode(newBlock(key))
+			if tc.Decrypt() {
+				got, err := mode.Decrypt(append(iv, ciphertext...))
+				if err != nil {
+					return err
+				}
+				return tc.Check("PLAINTEXT", got)
+			}
+
+			got, err := mode.EncryptWithIV(iv, plaintext)
+			if err != nil {
+				return err
+			}
+			return tc.Check("CIPHERTEXT", got[len(iv):])
+		}
+	}
+
+	cfbWithSegmentSize := func(segmentBits int) func(cipher.Block) modes.Mode {
+		return func(b cipher.Block) modes.Mode {
+			mode, err := modes.NewCFBWithSegmentSize(b, segmentBits)
+			Expect(err).NotTo(HaveOccurred())
+			return mode
+		}
+	}
+
+	knownAnswerTests := []struct {
+		file    string
+		newMode func(cipher.Block) modes.Mode
+	}{
+		{file: "CBCGFSbox128.rsp", newMode: modes.NewCBCNoPadding},
+		{file: "CBCSP800-38A.rsp", newMode: modes.NewCBCNoPadding},
+		{file: "CTRSP800-38A.rsp", newMode: modes.NewCTR},
+		{file: "CFB128GFSbox128.rsp", newMode: modes.NewCFB},
+		{file: "CFB128SP800-38A.rsp", newMode: cfbWithSegmentSize(128)},
+		{file: "CFB8GFSbox128.rsp", newMode: cfbWithSegmentSize(8)},
+		{file: "CFB8SP800-38A.rsp", newMode: cfbWithSegmentSize(8)},
+		{file: "CFB1SP800-38A.rsp", newMode: cfbWithSegmentSize(1)},
+		{file: "OFBGFSbox128.rsp", newMode: modes.NewOFB},
+		{file: "OFBSP800-38A.rsp", newMode: modes.NewOFB},
+	}
+
+	for _, kat := range knownAnswerTests {
+		kat := kat
+		It(fmt.Sprintf("should pass the known-answer tests of %s", kat.file), func() {
+			failures, err := cavp.Run(filepath.Join("..", "cavp", "testdata", kat.file), checkKnownAnswer(kat.newMode))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(failures).To(BeEmpty())
+		})
+	}
+
+	for name, newMode := range newModes {
+		name, newMode := name, newMode
+
+		It(fmt.Sprintf("should prefix the %s ciphertext with the IV and round-trip", name), func() {
+			key, err := modes.GenerateAESKey(16)
+			Expect(err).NotTo(HaveOccurred())
+			mode := newMode(newBlock(key))
+			Expect(mode.IVSize()).To(Equal(aes.BlockSize))
+
+			for _, size := range []int{0, 1, 15, 16, 17, 100} {
+				plaintext := make([]byte, size)
+				for i := range plaintext {
+					plaintext[i] = byte(i)
+				}
+
+				ciphertext, err := mode.Encrypt(plaintext)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(len(ciphertext)).To(BeNumerically(">=", aes.BlockSize+size))
+
+				decrypted, err := mode.Decrypt(ciphertext)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(decrypted).To(HaveLen(size))
+				Expect(decrypted).To(Equal(plaintext))
+
+				// The same IV gives the same ciphertext
+				again, err := mode.EncryptWithIV(ciphertext[:aes.BlockSize], plaintext)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(again).To(Equal(ciphertext))
+			}
+		})
+
+		It(fmt.Sprintf("should reject %s ciphertexts without an IV and IVs of the wrong size", name), func() {
+			mode := newMode(newBlock(make([]byte, 16)))
+
+			_, err := mode.Decrypt(make([]byte, aes.BlockSize-1))
+			Expect(err).To(MatchError(modes.ErrCiphertextTooShort))
+
+			_, err = mode.EncryptWithIV(make([]byte, 8), make([]byte, aes.BlockSize))
+			Expect(err).To(HaveOccurred())
+		})
+	}
+
+	It("should keep stream mode ciphertexts as long as the plaintext", func() {
+		for _, newMode := range []func(cipher.Block) modes.Mode{modes.NewCTR, modes.NewCFB, modes.NewOFB} {
+			ciphertext, err := newMode(newBlock(make([]byte, 16))).Encrypt([]byte("Hello World!"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ciphertext).To(HaveLen(aes.BlockSize + len("Hello World!")))
+		}
+	})
+
+	It("should reject CBC inputs that are not whole blocks", func() {
+		block := newBlock(make([]byte, 16))
+
+		_, err := modes.NewCBCNoPadding(block).Encrypt(make([]byte, 17))
+		Expect(err).To(HaveOccurred())
+
+		_, err = modes.NewCBC(block).Decrypt(make([]byte, aes.BlockSize+17))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should reject CBC ciphertexts with invalid padding", func() {
+		block := newBlock(make([]byte, 16))
+
+		// The last byte claims 2 bytes of padding, but the byte before it is not 2
+		invalid := append(make([]byte, aes.BlockSize-2), 0x01, 0x02)
+		ciphertext, err := modes.NewCBCNoPadding(block).Encrypt(invalid)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = modes.NewCBC(block).Decrypt(ciphertext)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should pad CBC plaintexts with the given padding scheme", func() {
+		block := newBlock(make([]byte, 16))
+		iv := make([]byte, aes.BlockSize)
+
+		for _, padder := range []padding.Padder{padding.PKCS7, padding.ANSIX923, padding.ISO7816} {
+			mode := modes.NewCBCWithPadding(block, padder)
+
+			ciphertext, err := mode.EncryptWithIV(iv, []byte("Hello world!"))
+			Expect(err).NotTo(HaveOccurred())
+
+			padded, err := modes.NewCBCNoPadding(block).Decrypt(ciphertext)
+			Expect(err).NotTo(HaveOccurred())
+			want, err := padder.Pad([]byte("Hello world!"), aes.BlockSize)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(padded).To(Equal(want))
+
+			decrypted, err := mode.Decrypt(ciphertext)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decrypted).To(Equal([]byte("Hello world!")))
+		}
+
+		// A block that does not end with valid PKCS#7 padding
+		ciphertext, err := modes.NewCBCNoPadding(block).EncryptWithIV(iv, []byte("YELLOW SUBMARINE"))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = modes.NewCBC(block).Decrypt(ciphertext)
+		Expect(err).To(MatchError(padding.ErrInvalidPadding))
+	})
+
+	It("should reject invalid AES key sizes", func() {
+		for _, size := range []int{16, 24, 32} {
+			key, err := modes.GenerateAESKey(size)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(key).To(HaveLen(size))
+		}
+
+		_, err := modes.GenerateAESKey(20)
+		Expect(err).To(HaveOccurred())
+	})
+})
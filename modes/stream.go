@@ -0,0 +1,53 @@
+package modes
+
+import "crypto/cipher"
+
+// streamMode turns a block cipher into a stream cipher, so the ciphertext is as long as the plaintext
+// and no padding is needed.
+type streamMode struct {
+	block     cipher.Block
+	encrypter func(cipher.Block, []byte) cipher.Stream
+	decrypter func(cipher.Block, []byte) cipher.Stream
+}
+
+// NewCTR returns the counter mode (CTR) of the given block cipher. The IV is the initial counter block,
+// incremented as a big-endian integer for every block.
+func NewCTR(b cipher.Block) Mode {
+	return &streamMode{block: b, encrypter: cipher.NewCTR, decrypter: cipher.NewCTR}
+}
+
+// NewOFB returns the output feedback mode (OFB) of the given block cipher.
+func NewOFB(b cipher.Block) Mode {
+	return &streamMode{block: b, encrypter: cipher.NewOFB, decrypter: cipher.NewOFB}
+}
+
+func (m *streamMode) IVSize() int {
+	return m.block.BlockSize()
+}
+
+func (m *streamMode) Encrypt(plaintext []byte) ([]byte, error) {
+	return encryptWithRandomIV(m, plaintext)
+}
+
+func (m *streamMode) EncryptWithIV(iv, plaintext []byte) ([]byte, error) {
+	ciphertext, body, err := frame(iv, m.IVSize(), len(plaintext))
+	if err != nil {
+		return nil, err
+	}
+
+	m.encrypter(m.block, iv).XORKeyStream(body, plaintext)
+
+	return ciphertext, nil
+}
+
+func (m *streamMode) Decrypt(ciphertext []byte) ([]byte, error) {
+	iv, body, err := unframe(ciphertext, m.IVSize())
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext := make([]byte, len(body))
+	m.decrypter(m.block, iv).XORKeyStream(plaintext, body)
+
+	return plaintext, nil
+}
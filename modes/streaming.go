@@ -0,0 +1,302 @@
+package modes
+
+import (
+	"crypto/cipher"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/japananh/crypto/padding"
+)
+
+// The streaming wrappers below produce and consume the same IV || ciphertext framing as the Mode
+// implementations, so a message written with NewCBCEncryptWriter can be decrypted with NewCBC and vice versa.
+// They only hold a few blocks in memory, whatever the size of the message.
+//
+// Closing a writer flushes the end of the message but does not close the underlying writer.
+
+var errWriterClosed = errors.New("modes: write to closed writer")
+
+// readChunkSize is how much ciphertext the decrypting readers request from the underlying reader at once.
+const readChunkSize = 32 * 1024
+
+// cbcEncryptWriter encrypts everything written to it with CBC, and pads the last block on Close.
+type cbcEncryptWriter struct {
+	w      io.Writer
+	mode   cipher.BlockMode
+	buf    []byte // plaintext of the incomplete last block
+	closed bool
+}
+
+// NewCBCEncryptWriter returns a writer that encrypts with CBC and PKCS#7 padding and writes the result to w.
+// It writes a random IV to w immediately. Whole blocks are encrypted as soon as they are complete,
+// the final partial block is padded when the writer is closed.
+func NewCBCEncryptWriter(b cipher.Block, w io.Writer) (io.WriteCloser, error) {
+	iv, err := writeIV(b, w)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cbcEncryptWriter{w: w, mode: cipher.NewCBCEncrypter(b, iv)}, nil
+}
+
+func (c *cbcEncryptWriter) Write(p []byte) (int, error) {
+	if c.closed {
+		return 0, errWriterClosed
+	}
+
+	blockSize := c.mode.BlockSize()
+	written := 0
+	for len(p) > 0 {
+		// Fill the buffer up to a bounded number of whole blocks, so large writes do not grow it
+		n := copy(c.free(readChunkSize), p)
+		c.buf = c.buf[:len(c.buf)+n]
+		p = p[n:]
+
+		whole := len(c.buf) / blockSize * blockSize
+		if whole > 0 {
+			c.mode.CryptBlocks(c.buf[:whole], c.buf[:whole])
+			if _, err := c.w.Write(c.buf[:whole]); err != nil {
+				return written, err
+			}
+			c.buf = c.buf[:copy(c.buf, c.buf[whole:])]
+		}
+		written += n
+	}
+
+	return written, nil
+}
+
+// free returns the unused capacity of buf, growing it to hold size bytes.
+func (c *cbcEncryptWriter) free(size int) []byte {
+	if cap(c.buf) < size {
+		grown := make([]byte, len(c.buf), size)
+		copy(grown, c.buf)
+		c.buf = grown
+	}
+	return c.buf[len(c.buf):cap(c.buf)]
+}
+
+// Close pads and encrypts the final block and writes it. It does not close the underlying writer.
+func (c *cbcEncryptWriter) Close() error {
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+
+	last, err := padding.PKCS7.Pad(c.buf, c.mode.BlockSize())
+	if err != nil {
+		return err
+	}
+	c.mode.CryptBlocks(last, last)
+
+	_, err = c.w.Write(last)
+	return err
+}
+
+// cbcDecryptReader decrypts CBC from an underlying reader. The last block is held back until the end
+// of the input, because only then is it known to carry the padding.
+type cbcDecryptReader struct {
+	r     io.Reader
+	block cipher.Block
+	mode  cipher.BlockMode
+	chunk []byte
+	buf   []byte // ciphertext read but not decrypted yet
+	out   []byte // decrypted plaintext not returned yet
+	err   error
+}
+
+// NewCBCDecryptReader returns a reader that decrypts IV || ciphertext read from r with CBC and removes the
+// PKCS#7 padding. Invalid padding or a truncated ciphertext is reported once the end of r is reached.
+func NewCBCDecryptReader(b cipher.Block, r io.Reader) io.Reader {
+	return &cbcDecryptReader{r: r, block: b}
+}
+
+func (c *cbcDecryptReader) Read(p []byte) (int, error) {
+	for len(c.out) == 0 {
+		if c.err != nil {
+			return 0, c.err
+		}
+		c.fill()
+	}
+
+	n := copy(p, c.out)
+	c.out = c.out[n:]
+	return n, nil
+}
+
+// fill reads the next chunk of ciphertext and decrypts every block that is known not to be the last one.
+func (c *cbcDecryptReader) fill() {
+	if c.mode == nil {
+		iv, err := readIV(c.block, c.r)
+		if err != nil {
+			c.err = err
+			return
+		}
+		c.mode = cipher.NewCBCDecrypter(c.block, iv)
+		c.chunk = make([]byte, readChunkSize)
+	}
+
+	n, err := c.r.Read(c.chunk)
+	c.buf = append(c.buf, c.chunk[:n]...)
+	if err == io.EOF {
+		c.finish()
+		return
+	}
+	if err != nil {
+		c.err = err
+		return
+	}
+
+	blockSize := c.block.BlockSize()
+	whole := len(c.buf) / blockSize * blockSize
+	if whole == len(c.buf) {
+		whole -= blockSize
+	}
+	if whole > 0 {
+		c.out = append(c.out[:0], c.buf[:whole]...)
+		c.mode.CryptBlocks(c.out, c.out)
+		c.buf = c.buf[:copy(c.buf, c.buf[whole:])]
+	}
+}
+
+// finish decrypts and unpads the last block once the underlying reader is exhausted.
+func (c *cbcDecryptReader) finish() {
+	blockSize := c.block.BlockSize()
+	if len(c.buf) == 0 || len(c.buf)%blockSize != 0 {
+		c.err = fmt.Errorf("modes: CBC ciphertext must be a non-empty multiple of %d bytes: %w", blockSize, io.ErrUnexpectedEOF)
+		return
+	}
+
+	last := append(c.out[:0], c.buf...)
+	c.mode.CryptBlocks(last, last)
+	c.buf = nil
+
+	c.out, c.err = padding.PKCS7.Unpad(last, blockSize)
+	if c.err == nil {
+		c.err = io.EOF
+	}
+}
+
+// streamEncryptWriter encrypts with a stream mode. Unlike cipher.StreamWriter, Close does not close
+// the underlying writer, to match the CBC writer.
+type streamEncryptWriter struct {
+	w      io.Writer
+	stream cipher.Stream
+	buf    []byte
+	closed bool
+}
+
+// NewCFBEncryptWriter returns a writer that encrypts with full-block CFB and writes the result to w.
+// It writes a random IV to w immediately.
+func NewCFBEncryptWriter(b cipher.Block, w io.Writer) (io.WriteCloser, error) {
+	iv, err := writeIV(b, w)
+	if err != nil {
+		return nil, err
+	}
+
+	return &streamEncryptWriter{w: w, stream: newCFBStream(b, iv, 8*b.BlockSize(), false)}, nil
+}
+
+// NewOFBEncryptWriter returns a writer that encrypts with OFB and writes the result to w.
+// It writes a random IV to w immediately.
+func NewOFBEncryptWriter(b cipher.Block, w io.Writer) (io.WriteCloser, error) {
+	iv, err := writeIV(b, w)
+	if err != nil {
+		return nil, err
+	}
+
+	return &streamEncryptWriter{w: w, stream: cipher.NewOFB(b, iv)}, nil
+}
+
+func (s *streamEncryptWriter) Write(p []byte) (int, error) {
+	if s.closed {
+		return 0, errWriterClosed
+	}
+
+	written := 0
+	for len(p) > 0 {
+		n := len(p)
+		if n > readChunkSize {
+			n = readChunkSize
+		}
+		if s.buf == nil {
+			s.buf = make([]byte, readChunkSize)
+		}
+
+		s.stream.XORKeyStream(s.buf[:n], p[:n])
+		if _, err := s.w.Write(s.buf[:n]); err != nil {
+			return written, err
+		}
+		written += n
+		p = p[n:]
+	}
+
+	return written, nil
+}
+
+// Close marks the writer as closed. Stream modes need no padding, so there is nothing to flush.
+func (s *streamEncryptWriter) Close() error {
+	s.closed = true
+	return nil
+}
+
+// streamDecryptReader decrypts a stream mode, reading the IV before the first byte of ciphertext.
+type streamDecryptReader struct {
+	r         io.Reader
+	block     cipher.Block
+	newStream func(iv []byte) cipher.Stream
+	stream    cipher.Stream
+}
+
+// NewCFBDecryptReader returns a reader that decrypts IV || ciphertext read from r with full-block CFB.
+func NewCFBDecryptReader(b cipher.Block, r io.Reader) io.Reader {
+	return &streamDecryptReader{r: r, block: b, newStream: func(iv []byte) cipher.Stream {
+		return newCFBStream(b, iv, 8*b.BlockSize(), true)
+	}}
+}
+
+// NewOFBDecryptReader returns a reader that decrypts IV || ciphertext read from r with OFB.
+func NewOFBDecryptReader(b cipher.Block, r io.Reader) io.Reader {
+	return &streamDecryptReader{r: r, block: b, newStream: func(iv []byte) cipher.Stream {
+		return cipher.NewOFB(b, iv)
+	}}
+}
+
+func (s *streamDecryptReader) Read(p []byte) (int, error) {
+	if s.stream == nil {
+		iv, err := readIV(s.block, s.r)
+		if err != nil {
+			return 0, err
+		}
+		s.stream = s.newStream(iv)
+	}
+
+	n, err := s.r.Read(p)
+	s.stream.XORKeyStream(p[:n], p[:n])
+	return n, err
+}
+
+// writeIV writes a fresh random IV to w and returns it.
+func writeIV(b cipher.Block, w io.Writer) ([]byte, error) {
+	iv, err := GenerateIV(b.BlockSize())
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(iv); err != nil {
+		return nil, err
+	}
+	return iv, nil
+}
+
+// readIV reads the IV that starts a ciphertext.
+func readIV(b cipher.Block, r io.Reader) ([]byte, error) {
+	iv := make([]byte, b.BlockSize())
+	if _, err := io.ReadFull(r, iv); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, ErrCiphertextTooShort
+		}
+		return nil, err
+	}
+	return iv, nil
+}
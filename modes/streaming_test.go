@@ -0,0 +1,159 @@
+package modes_test
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"testing/iotest"
+
+	"github.com/japananh/crypto/modes"
+	"github.com/japananh/crypto/padding"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("modes - streaming", func() {
+	type streamingMode struct {
+		name      string
+		newWriter func(cipher.Block, io.Writer) (io.WriteCloser, error)
+		newReader func(cipher.Block, io.Reader) io.Reader
+		mode      func(cipher.Block) modes.Mode
+	}
+
+	streamingModes := []streamingMode{
+		{name: "CBC", newWriter: modes.NewCBCEncryptWriter, newReader: modes.NewCBCDecryptReader, mode: modes.NewCBC},
+		{name: "CFB", newWriter: modes.NewCFBEncryptWriter, newReader: modes.NewCFBDecryptReader, mode: modes.NewCFB},
+		{name: "OFB", newWriter: modes.NewOFBEncryptWriter, newReader: modes.NewOFBDecryptReader, mode: modes.NewOFB},
+	}
+
+	var block cipher.Block
+
+	BeforeEach(func() {
+		var err error
+		block, err = aes.NewCipher([]byte("YELLOW SUBMARINE"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	message := func(size int) []byte {
+		data := make([]byte, size)
+		for i := range data {
+			data[i] = byte(i * 7)
+		}
+		return data
+	}
+
+	// encryptInPieces writes plaintext through the encrypting writer in pieces of growing size
+	encryptInPieces := func(m streamingMode, plaintext []byte) []byte {
+		var ciphertext bytes.Buffer
+		w, err := m.newWriter(block, &ciphertext)
+		Expect(err).NotTo(HaveOccurred())
+
+		for start, size := 0, 1; start < len(plaintext); start, size = start+size, size+5 {
+			end := start + size
+			if end > len(plaintext) {
+				end = len(plaintext)
+			}
+			n, err := w.Write(plaintext[start:end])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(n).To(Equal(end - start))
+		}
+		Expect(w.Close()).To(Succeed())
+
+		return ciphertext.Bytes()
+	}
+
+	for _, m := range streamingModes {
+		m := m
+
+		It(fmt.Sprintf("should write %s ciphertexts that the %s mode decrypts", m.name, m.name), func() {
+			for _, size := range []int{0, 1, 15, 16, 17, 31, 32, 33, 1000} {
+				plaintext := message(size)
+				ciphertext := encryptInPieces(m, plaintext)
+
+				decrypted, err := m.mode(block).Decrypt(ciphertext)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(decrypted).To(Equal(plaintext))
+			}
+		})
+
+		It(fmt.Sprintf("should read %s ciphertexts of the %s mode one byte at a time", m.name, m.name), func() {
+			for _, size := range []int{0, 1, 15, 16, 17, 31, 32, 33, 1000} {
+				plaintext := message(size)
+				ciphertext, err := m.mode(block).Encrypt(plaintext)
+				Expect(err).NotTo(HaveOccurred())
+
+				decrypted, err := io.ReadAll(m.newReader(block, iotest.OneByteReader(bytes.NewReader(ciphertext))))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(decrypted).To(Equal(plaintext))
+
+				decrypted, err = io.ReadAll(m.newReader(block, iotest.DataErrReader(bytes.NewReader(ciphertext))))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(decrypted).To(Equal(plaintext))
+			}
+		})
+
+		It(fmt.Sprintf("should stream %s through a pipe with bounded memory", m.name), func() {
+			// 8 MiB and a partial block, hashed on both ends instead of being kept in memory
+			const size = 8<<20 + 7
+			source := io.LimitReader(bytes.NewReader(bytes.Repeat(message(4093), size/4093+1)), size)
+
+			pipeReader, pipeWriter := io.Pipe()
+			sent := sha256.New()
+			go func() {
+				defer GinkgoRecover()
+				w, err := m.newWriter(block, pipeWriter)
+				Expect(err).NotTo(HaveOccurred())
+				_, err = io.Copy(w, io.TeeReader(source, sent))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(w.Close()).To(Succeed())
+				Expect(pipeWriter.Close()).To(Succeed())
+			}()
+
+			received := sha256.New()
+			n, err := io.Copy(received, m.newReader(block, pipeReader))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(n).To(Equal(int64(size)))
+			Expect(received.Sum(nil)).To(Equal(sent.Sum(nil)))
+		})
+
+		It(fmt.Sprintf("should reject %s ciphertexts without an IV and writes after Close", m.name), func() {
+			_, err := io.ReadAll(m.newReader(block, bytes.NewReader(make([]byte, aes.BlockSize-1))))
+			Expect(err).To(MatchError(modes.ErrCiphertextTooShort))
+
+			w, err := m.newWriter(block, io.Discard)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(w.Close()).To(Succeed())
+			_, err = w.Write([]byte("too late"))
+			Expect(err).To(HaveOccurred())
+		})
+	}
+
+	It("should report truncated CBC ciphertexts and invalid padding at the end of the input", func() {
+		ciphertext, err := modes.NewCBC(block).Encrypt(message(40))
+		Expect(err).NotTo(HaveOccurred())
+
+		decrypted, err := io.ReadAll(modes.NewCBCDecryptReader(block, bytes.NewReader(ciphertext[:len(ciphertext)-1])))
+		Expect(err).To(MatchError(io.ErrUnexpectedEOF))
+		Expect(decrypted).To(Equal(message(32)))
+
+		_, err = io.ReadAll(modes.NewCBCDecryptReader(block, bytes.NewReader(ciphertext[:aes.BlockSize])))
+		Expect(err).To(MatchError(io.ErrUnexpectedEOF))
+
+		unpadded, err := modes.NewCBCNoPadding(block).Encrypt(message(48))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = io.ReadAll(modes.NewCBCDecryptReader(block, bytes.NewReader(unpadded)))
+		Expect(err).To(MatchError(padding.ErrInvalidPadding))
+	})
+
+	It("should pass on errors of the underlying reader", func() {
+		readErr := fmt.Errorf("disk on fire")
+		r := io.MultiReader(bytes.NewReader(make([]byte, 40)), iotest.ErrReader(readErr))
+
+		_, err := io.ReadAll(modes.NewCBCDecryptReader(block, r))
+		Expect(err).To(MatchError(readErr))
+	})
+})
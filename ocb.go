@@ -0,0 +1,233 @@
+package crypto
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+const (
+	// OCBNonceSize is the nonce size used by NewOCB, the 96-bit nonce recommended by RFC 7253.
+	OCBNonceSize = 12
+	// OCBTagSize is the size of the authentication tag appended by OCB.
+	OCBTagSize = 16
+)
+
+var errOpen = errors.New("message authentication failed")
+
+// ocb implements OCB3 as specified in RFC 7253 with a 128-bit tag.
+type ocb struct {
+	block cipher.Block
+
+	// lStar = E(0), lDollar = L_* · x, l[i] = L_$ · x^(i+1). l[ntz(i)] is the offset increment for block i.
+	lStar   [macBlockSize]byte
+	lDollar [macBlockSize]byte
+	l       [64][macBlockSize]byte
+}
+
+// NewOCB returns the given 128-bit block cipher wrapped in OCB3 (RFC 7253) with a 12-byte nonce and a 16-byte tag.
+//
+// OCB encrypts and authenticates in a single pass with one block cipher call per block,
+// whereas GCM and EAX need a separate authentication pass over the ciphertext.
+func NewOCB(b cipher.Block) (cipher.AEAD, error) {
+	if b.BlockSize() != macBlockSize {
+		return nil, fmt.Errorf("ocb: block size must be %d bytes, got %d", macBlockSize, b.BlockSize())
+	}
+
+	o := &ocb{block: b}
+	b.Encrypt(o.lStar[:], o.lStar[:])
+	o.lDollar = gfDouble(o.lStar)
+	o.l[0] = gfDouble(o.lDollar)
+	for i := 1; i < len(o.l); i++ {
+		o.l[i] = gfDouble(o.l[i-1])
+	}
+
+	return o, nil
+}
+
+func (o *ocb) NonceSize() int {
+	return OCBNonceSize
+}
+
+func (o *ocb) Overhead() int {
+	return OCBTagSize
+}
+
+func (o *ocb) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != OCBNonceSize {
+		panic("crypto/ocb: incorrect nonce length given to OCB")
+	}
+
+	ret, out := sliceForAppend(dst, len(plaintext)+OCBTagSize)
+	checksum := o.crypt(out, plaintext, nonce, true)
+
+	tag := o.tag(checksum, nonce, additionalData)
+	copy(out[len(plaintext):], tag[:])
+
+	return ret
+}
+
+func (o *ocb) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != OCBNonceSize {
+		panic("crypto/ocb: incorrect nonce length given to OCB")
+	}
+	if len(ciphertext) < OCBTagSize {
+		return nil, errOpen
+	}
+
+	tag := ciphertext[len(ciphertext)-OCBTagSize:]
+	ciphertext = ciphertext[:len(ciphertext)-OCBTagSize]
+
+	ret, out := sliceForAppend(dst, len(ciphertext))
+	checksum := o.crypt(out, ciphertext, nonce, false)
+
+	expectedTag := o.tag(checksum, nonce, additionalData)
+	if subtle.ConstantTimeCompare(expectedTag[:], tag) != 1 {
+		// Do not release unauthenticated plaintext
+		for i := range out {
+			out[i] = 0
+		}
+		return nil, errOpen
+	}
+
+	return ret, nil
+}
+
+// initialOffset derives Offset_0 from the nonce (RFC 7253, section 4.2).
+func (o *ocb) initialOffset(nonce []byte) [macBlockSize]byte {
+	// Nonce = num2str(TAGLEN mod 128, 7) || zeros || 1 || N; with a 128-bit tag the first 7 bits are zero
+	var n [macBlockSize]byte
+	copy(n[macBlockSize-len(nonce):], nonce)
+	n[macBlockSize-len(nonce)-1] |= 1
+
+	bottom := uint(n[macBlockSize-1] & 0x3f)
+	n[macBlockSize-1] &= 0xc0
+
+	var kTop [macBlockSize]byte
+	o.block.Encrypt(kTop[:], n[:])
+
+	// Stretch = Ktop || (Ktop[1..64] xor Ktop[9..72])
+	var stretch [macBlockSize + 8]byte
+	copy(stretch[:], kTop[:])
+	for i := 0; i < 8; i++ {
+		stretch[macBlockSize+i] = kTop[i] ^ kTop[i+1]
+	}
+
+	// Offset_0 = Stretch[1+bottom..128+bottom], a 128-bit window starting at bit "bottom"
+	var offset [macBlockSize]byte
+	byteShift, bitShift := bottom/8, bottom%8
+	for i := range offset {
+		offset[i] = stretch[uint(i)+byteShift] << bitShift
+		if bitShift > 0 {
+			offset[i] |= stretch[uint(i)+byteShift+1] >> (8 - bitShift)
+		}
+	}
+
+	return offset
+}
+
+// crypt encrypts or decrypts src into dst and returns the checksum of the plaintext.
+// The final offset is folded into the checksum so tag only needs to add L_$ and the associated data hash.
+func (o *ocb) crypt(dst, src, nonce []byte, encrypt bool) [macBlockSize]byte {
+	offset := o.initialOffset(nonce)
+	var checksum [macBlockSize]byte
+
+	i := uint64(0)
+	for ; len(src) >= macBlockSize; i++ {
+		subtle.XORBytes(offset[:], offset[:], o.l[bits.TrailingZeros64(i+1)][:])
+
+		var buf [macBlockSize]byte
+		subtle.XORBytes(buf[:], src[:macBlockSize], offset[:])
+		if encrypt {
+			subtle.XORBytes(checksum[:], checksum[:], src[:macBlockSize])
+			o.block.Encrypt(buf[:], buf[:])
+		} else {
+			o.block.Decrypt(buf[:], buf[:])
+		}
+		subtle.XORBytes(dst[:macBlockSize], buf[:], offset[:])
+		if !encrypt {
+			subtle.XORBytes(checksum[:], checksum[:], dst[:macBlockSize])
+		}
+
+		src = src[macBlockSize:]
+		dst = dst[macBlockSize:]
+	}
+
+	if len(src) > 0 {
+		// The final partial block is encrypted with a keystream pad, like CTR mode
+		subtle.XORBytes(offset[:], offset[:], o.lStar[:])
+
+		var pad [macBlockSize]byte
+		o.block.Encrypt(pad[:], offset[:])
+		subtle.XORBytes(dst, src, pad[:len(src)])
+
+		plain := src
+		if !encrypt {
+			plain = dst[:len(src)]
+		}
+		var last [macBlockSize]byte
+		copy(last[:], plain)
+		last[len(plain)] = 0x80
+		subtle.XORBytes(checksum[:], checksum[:], last[:])
+	}
+
+	subtle.XORBytes(checksum[:], checksum[:], offset[:])
+
+	return checksum
+}
+
+// tag computes Tag = E(Checksum xor Offset xor L_$) xor HASH(K, A).
+func (o *ocb) tag(checksumWithOffset [macBlockSize]byte, nonce, additionalData []byte) [macBlockSize]byte {
+	var tag [macBlockSize]byte
+	subtle.XORBytes(tag[:], checksumWithOffset[:], o.lDollar[:])
+	o.block.Encrypt(tag[:], tag[:])
+
+	hash := o.hash(additionalData)
+	subtle.XORBytes(tag[:], tag[:], hash[:])
+
+	return tag
+}
+
+// hash computes HASH(K, A), the PMAC-like hash of the associated data (RFC 7253, section 4.1).
+func (o *ocb) hash(additionalData []byte) [macBlockSize]byte {
+	var sum, offset, buf [macBlockSize]byte
+
+	i := uint64(0)
+	for ; len(additionalData) >= macBlockSize; i++ {
+		subtle.XORBytes(offset[:], offset[:], o.l[bits.TrailingZeros64(i+1)][:])
+		subtle.XORBytes(buf[:], additionalData[:macBlockSize], offset[:])
+		o.block.Encrypt(buf[:], buf[:])
+		subtle.XORBytes(sum[:], sum[:], buf[:])
+
+		additionalData = additionalData[macBlockSize:]
+	}
+
+	if len(additionalData) > 0 {
+		subtle.XORBytes(offset[:], offset[:], o.lStar[:])
+
+		buf = [macBlockSize]byte{}
+		copy(buf[:], additionalData)
+		buf[len(additionalData)] = 0x80
+		subtle.XORBytes(buf[:], buf[:], offset[:])
+		o.block.Encrypt(buf[:], buf[:])
+		subtle.XORBytes(sum[:], sum[:], buf[:])
+	}
+
+	return sum
+}
+
+// sliceForAppend takes a slice and a requested number of bytes. It returns a slice with the contents of the
+// given slice followed by that many bytes and a second slice that aliases into it and contains only the extra
+// bytes, the same helper crypto/cipher uses for its AEAD implementations.
+func sliceForAppend(in []byte, n int) (head, tail []byte) {
+	if total := len(in) + n; cap(in) >= total {
+		head = in[:total]
+	} else {
+		head = make([]byte, total)
+		copy(head, in)
+	}
+	tail = head[len(in):]
+	return
+}
@@ -0,0 +1,153 @@
+package crypto_test
+
+import (
+	"crypto/aes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/japananh/crypto"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("crypto - ocb", func() {
+	decodeHex := func(s string) []byte {
+		b, err := hex.DecodeString(s)
+		Expect(err).NotTo(HaveOccurred())
+		return b
+	}
+
+	// sequentialBytes returns 00 01 02 ... of the given length, the input format used by RFC 7253.
+	sequentialBytes := func(length int) []byte {
+		b := make([]byte, length)
+		for i := range b {
+			b[i] = byte(i)
+		}
+		return b
+	}
+
+	// Sample results from RFC 7253, Appendix A, with K = 000102030405060708090A0B0C0D0E0F
+	ocbTestVectors := []struct {
+		nonce      string
+		adLen      int
+		ptLen      int
+		ciphertext string
+	}{
+		{nonce: "BBAA99887766554433221100", adLen: 0, ptLen: 0, ciphertext: "785407BFFFC8AD9EDCC5520AC9111EE6"},
+		{nonce: "BBAA99887766554433221101", adLen: 8, ptLen: 8, ciphertext: "6820B3657B6F615A5725BDA0D3B4EB3A257C9AF1F8F03009"},
+		{nonce: "BBAA99887766554433221102", adLen: 8, ptLen: 0, ciphertext: "81017F8203F081277152FADE694A0A00"},
+		{nonce: "BBAA99887766554433221103", adLen: 0, ptLen: 8, ciphertext: "45DD69F8F5AAE72414054CD1F35D82760B2CD00D2F99BFA9"},
+		{nonce: "BBAA99887766554433221104", adLen: 16, ptLen: 16, ciphertext: "571D535B60B277188BE5147170A9A22C3AD7A4FF3835B8C5701C1CCEC8FC3358"},
+		{nonce: "BBAA99887766554433221105", adLen: 16, ptLen: 0, ciphertext: "8CF761B6902EF764462AD86498CA6B97"},
+		{nonce: "BBAA99887766554433221106", adLen: 0, ptLen: 16, ciphertext: "5CE88EC2E0692706A915C00AEB8B2396F40E1C743F52436BDF06D8FA1ECA343D"},
+		{nonce: "BBAA99887766554433221107", adLen: 24, ptLen: 24, ciphertext: "1CA2207308C87C010756104D8840CE1952F09673A448A122C92C62241051F57356D7F3C90BB0E07F"},
+		{nonce: "BBAA99887766554433221108", adLen: 24, ptLen: 0, ciphertext: "6DC225A071FC1B9F7C69F93B0F1E10DE"},
+		{nonce: "BBAA99887766554433221109", adLen: 0, ptLen: 24, ciphertext: "221BD0DE7FA6FE993ECCD769460A0AF2D6CDED0C395B1C3CE725F32494B9F914D85C0B1EB38357FF"},
+		{nonce: "BBAA9988776655443322110A", adLen: 32, ptLen: 32, ciphertext: "BD6F6C496201C69296C11EFD138A467ABD3C707924B964DEAFFC40319AF5A48540FBBA186C5553C68AD9F592A79A4240"},
+	}
+
+	Describe("Seal - Open", func() {
+		for _, tt := range ocbTestVectors {
+			tt := tt
+			It("should match RFC 7253 sample with N = "+tt.nonce, func() {
+				block, err := aes.NewCipher(sequentialBytes(16))
+				Expect(err).NotTo(HaveOccurred())
+				aead, err := crypto.NewOCB(block)
+				Expect(err).NotTo(HaveOccurred())
+
+				nonce := decodeHex(tt.nonce)
+				additionalData := sequentialBytes(tt.adLen)
+				plaintext := sequentialBytes(tt.ptLen)
+
+				ciphertext := aead.Seal(nil, nonce, plaintext, additionalData)
+				Expect(strings.ToUpper(hex.EncodeToString(ciphertext))).To(Equal(tt.ciphertext))
+
+				decrypted, err := aead.Open(nil, nonce, ciphertext, additionalData)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(decrypted).To(HaveLen(tt.ptLen))
+				Expect(decrypted).To(Equal(plaintext[:len(decrypted)]))
+			})
+		}
+
+		// RFC 7253, Appendix A: the iterated test encrypts strings of every length from 0 to 127 bytes
+		for _, tt := range []struct {
+			keyLen int
+			output string
+		}{
+			{keyLen: 16, output: "67E944D23256C5E0B6C61FA22FDF1EA2"},
+			{keyLen: 24, output: "F673F2C3E7174AAE7BAE986CA9F29E17"},
+			{keyLen: 32, output: "D90EB8E9C977C88B79DD793D7FFA161C"},
+		} {
+			tt := tt
+			It(fmt.Sprintf("should match the RFC 7253 iterated test with a %d-bit key", tt.keyLen*8), func() {
+				key := make([]byte, tt.keyLen)
+				key[len(key)-1] = 128 // TAGLEN
+				block, err := aes.NewCipher(key)
+				Expect(err).NotTo(HaveOccurred())
+				aead, err := crypto.NewOCB(block)
+				Expect(err).NotTo(HaveOccurred())
+
+				nonce := make([]byte, crypto.OCBNonceSize)
+				setNonce := func(n int) []byte {
+					binary.BigEndian.PutUint32(nonce[8:], uint32(n))
+					return nonce
+				}
+
+				var c []byte
+				for i := 0; i < 128; i++ {
+					s := make([]byte, i)
+					c = aead.Seal(c, setNonce(3*i+1), s, s)
+					c = aead.Seal(c, setNonce(3*i+2), s, nil)
+					c = aead.Seal(c, setNonce(3*i+3), nil, s)
+				}
+
+				output := aead.Seal(nil, setNonce(385), nil, c)
+				Expect(strings.ToUpper(hex.EncodeToString(output))).To(Equal(tt.output))
+			})
+		}
+
+		It("should encrypt and decrypt in place", func() {
+			block, err := aes.NewCipher(randomBytes(32))
+			Expect(err).NotTo(HaveOccurred())
+			aead, err := crypto.NewOCB(block)
+			Expect(err).NotTo(HaveOccurred())
+
+			nonce := randomBytes(aead.NonceSize())
+			plaintext := randomBytes(100)
+			buf := append([]byte(nil), plaintext...)
+
+			ciphertext := aead.Seal(buf[:0], nonce, buf, nil)
+			Expect(ciphertext).To(HaveLen(len(plaintext) + aead.Overhead()))
+
+			decrypted, err := aead.Open(ciphertext[:0], nonce, ciphertext, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decrypted).To(Equal(plaintext))
+		})
+
+		It("should reject a tampered ciphertext, tag or associated data", func() {
+			block, err := aes.NewCipher(randomBytes(16))
+			Expect(err).NotTo(HaveOccurred())
+			aead, err := crypto.NewOCB(block)
+			Expect(err).NotTo(HaveOccurred())
+
+			nonce := randomBytes(aead.NonceSize())
+			ciphertext := aead.Seal(nil, nonce, []byte("attack at dawn"), []byte("header"))
+
+			for i := range ciphertext {
+				tampered := append([]byte(nil), ciphertext...)
+				tampered[i] ^= 1
+				_, err := aead.Open(nil, nonce, tampered, []byte("header"))
+				Expect(err).To(HaveOccurred())
+			}
+
+			_, err = aead.Open(nil, nonce, ciphertext, []byte("Header"))
+			Expect(err).To(HaveOccurred())
+
+			_, err = aead.Open(nil, nonce, ciphertext[:aead.Overhead()-1], nil)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -2,65 +2,54 @@ package main
 
 import (
 	"crypto/aes"
-	"crypto/cipher"
-	"crypto/rand"
 	"fmt"
+
+	"github.com/japananh/crypto/modes"
 )
 
-// generateAESKey generates an AES key, either 16, 24, or 32 bytes to select AES-128, AES-192, or AES-256
-func generateAESKey(size int) ([]byte, error) {
-	if size != 16 && size != 24 && size != 32 {
-		return nil, fmt.Errorf("AES key size must be 16, 24 or 32 bytes")
-	}
-	key := make([]byte, size)
-	if _, err := rand.Read(key); err != nil {
+// ivGuard remembers the IVs used by encrypt and encryptWithIV, and rejects an IV equal to an earlier one
+var ivGuard = modes.NewMemoryIVGuard(1 << 16)
+
+// encrypt encrypts plaintext to ciphertext using OFB mode and returns IV || ciphertext
+func encrypt(key, plaintext []byte) ([]byte, error) {
+	mode, err := newGuardedMode(key)
+	if err != nil {
 		return nil, err
 	}
-	return key, nil
+
+	return mode.Encrypt(plaintext)
 }
 
-// generateIV generates an IV (Initialization Vector). IV must match the block size of cipher used.
-func generateIV(size int) ([]byte, error) {
-	iv := make([]byte, size)
-	_, err := rand.Read(iv)
+// encryptWithIV is like encrypt, but uses the given IV. It fails with modes.ErrIVReused if the IV was already
+// used with the same key.
+func encryptWithIV(key, iv, plaintext []byte) ([]byte, error) {
+	mode, err := newGuardedMode(key)
 	if err != nil {
 		return nil, err
 	}
-	return iv, nil
+
+	return mode.EncryptWithIV(iv, plaintext)
 }
 
-func encrypt(key, iv, plaintext []byte) ([]byte, error) {
+// newGuardedMode returns OFB mode for the given key, checked by ivGuard
+func newGuardedMode(key []byte) (modes.Mode, error) {
 	// Create AES block
 	block, err := aes.NewCipher(key)
 	if err != nil {
 		return nil, err
 	}
 
-	// Create the OFB stream with the IV and AES cipher
-	stream := cipher.NewOFB(block, iv)
-
-	// Encrypt the plaintext
-	ciphertext := make([]byte, len(plaintext))
-	stream.XORKeyStream(ciphertext, plaintext)
-
-	return ciphertext, nil
+	return modes.WithIVGuard(modes.NewOFB(block), ivGuard)
 }
 
-func decrypt(key, iv, ciphertext []byte) ([]byte, error) {
+// decrypt decrypts IV || ciphertext using OFB mode
+func decrypt(key, ciphertext []byte) ([]byte, error) {
 	block, err := aes.NewCipher(key)
 	if err != nil {
 		return nil, err
 	}
 
-	// Create the OFB stream with the IV and AES cipher
-	stream := cipher.NewOFB(block, iv)
-
-	// Decrypt the ciphertext
-	stream = cipher.NewOFB(block, iv)
-	decrypted := make([]byte, len(ciphertext))
-	stream.XORKeyStream(decrypted, ciphertext)
-
-	return decrypted, nil
+	return modes.NewOFB(block).Decrypt(ciphertext)
 }
 
 func main() {
@@ -69,19 +58,13 @@ func main() {
 	fmt.Printf("Plaintext: %s\n", plaintext)
 
 	// Create the AES cipher using a 32 byte key
-	key, err := generateAESKey(32)
-	if err != nil {
-		panic(err)
-	}
-
-	// Create a 16 byte random initialization vector
-	iv, err := generateIV(aes.BlockSize)
+	key, err := modes.GenerateAESKey(32)
 	if err != nil {
 		panic(err)
 	}
 
-	// Encrypt the plaintext
-	ciphertext, err := encrypt(key, iv, plaintext)
+	// Encrypt the plaintext under a 16 byte random initialization vector, which prefixes the ciphertext
+	ciphertext, err := encrypt(key, plaintext)
 	if err != nil {
 		panic(err)
 	}
@@ -89,7 +72,7 @@ func main() {
 	fmt.Printf("Encrypted: %x\n", ciphertext)
 
 	// Decrypt the ciphertext
-	decrypted, err := decrypt(key, iv, ciphertext)
+	decrypted, err := decrypt(key, ciphertext)
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/japananh/crypto/modes"
+)
+
+func Test_EncryptDecrypt(t *testing.T) {
+	key := []byte("YELLOW SUBMARINE")
+
+	tests := []struct {
+		name      string
+		plaintext []byte
+	}{
+		{name: "Test empty message", plaintext: []byte{}},
+		{name: "Test whole block", plaintext: []byte("sixteen byte msg")},
+		{name: "Test partial block", plaintext: []byte("This is a sample message to be encrypted using OFB mode.")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ciphertext, err := encrypt(key, tt.plaintext)
+			if err != nil {
+				t.Fatalf("encrypt() error = %v", err)
+			}
+			got, err := decrypt(key, ciphertext)
+			if err != nil {
+				t.Fatalf("decrypt() error = %v", err)
+			}
+			if !bytes.Equal(got, tt.plaintext) {
+				t.Errorf("decrypt() got = %q, want %q", got, tt.plaintext)
+			}
+		})
+	}
+}
+
+func Test_DecryptTooShort(t *testing.T) {
+	if _, err := decrypt([]byte("YELLOW SUBMARINE"), make([]byte, 15)); err == nil {
+		t.Errorf("decrypt() error = nil, want error")
+	}
+}
+
+func Test_EncryptWithIVReused(t *testing.T) {
+	key := []byte("YELLOW SUBMARINE")
+	otherKey := []byte("ORANGE SUBMARINE")
+	iv := []byte("fixed IV 16 byte")
+
+	if _, err := encryptWithIV(key, iv, []byte("first message")); err != nil {
+		t.Fatalf("encryptWithIV() error = %v", err)
+	}
+	if _, err := encryptWithIV(key, iv, []byte("second message")); !errors.Is(err, modes.ErrIVReused) {
+		t.Errorf("encryptWithIV() with a reused IV error = %v, want %v", err, modes.ErrIVReused)
+	}
+
+	// The same IV is fine under another key
+	if _, err := encryptWithIV(otherKey, iv, []byte("second message")); err != nil {
+		t.Errorf("encryptWithIV() with another key error = %v", err)
+	}
+}
+
+func Test_EncryptFreshIVs(t *testing.T) {
+	key := []byte("YELLOW SUBMARINE")
+
+	// encrypt draws a random IV for every message, so the guard never fires
+	for i := 0; i < 100; i++ {
+		if _, err := encrypt(key, []byte("same message")); err != nil {
+			t.Fatalf("encrypt() error = %v", err)
+		}
+	}
+}
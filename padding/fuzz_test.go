@@ -0,0 +1,78 @@
+package padding_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/japananh/crypto/padding"
+)
+
+var padders = map[string]padding.Padder{
+	"PKCS7":    padding.PKCS7,
+	"ANSIX923": padding.ANSIX923,
+	"ISO7816":  padding.ISO7816,
+	"ISO10126": padding.ISO10126,
+	"Zero":     padding.Zero,
+}
+
+func FuzzRoundTrip(f *testing.F) {
+	f.Add([]byte(""), uint8(16))
+	f.Add([]byte("Hello world!"), uint8(16))
+	f.Add([]byte("YELLOW SUBMARINE"), uint8(16))
+	f.Add([]byte("\x80\x00\x01"), uint8(8))
+	f.Add(bytes.Repeat([]byte{0xff}, 300), uint8(255))
+
+	f.Fuzz(func(t *testing.T, data []byte, blockSize uint8) {
+		if blockSize == 0 {
+			t.Skip()
+		}
+
+		for name, padder := range padders {
+			// Zero padding cannot tell trailing zero bytes of the message from padding
+			if name == "Zero" && len(data) > 0 && data[len(data)-1] == 0 {
+				continue
+			}
+
+			padded, err := padder.Pad(data, int(blockSize))
+			if err != nil {
+				t.Fatalf("%s: Pad() error = %v", name, err)
+			}
+			if len(padded)%int(blockSize) != 0 || !bytes.Equal(padded[:len(data)], data) {
+				t.Fatalf("%s: Pad() = %x, not a multiple of %d starting with %x", name, padded, blockSize, data)
+			}
+
+			unpadded, err := padder.Unpad(padded, int(blockSize))
+			if err != nil {
+				t.Fatalf("%s: Unpad() error = %v", name, err)
+			}
+			if !bytes.Equal(unpadded, data) {
+				t.Fatalf("%s: Unpad() = %x, want %x", name, unpadded, data)
+			}
+		}
+	})
+}
+
+func FuzzUnpad(f *testing.F) {
+	f.Add([]byte("Hello world!\x04\x04\x04\x04"), uint8(16))
+	f.Add([]byte("Hello world!\x04\x03\x04\x04"), uint8(16))
+	f.Add([]byte("Hello world!\x80\x00\x00\x00"), uint8(16))
+	f.Add(make([]byte, 16), uint8(16))
+
+	f.Fuzz(func(t *testing.T, data []byte, blockSize uint8) {
+		if blockSize == 0 {
+			t.Skip()
+		}
+
+		// Unpad must never panic, and whatever it accepts must be a prefix of the input
+		// no more than one block shorter
+		for name, padder := range padders {
+			unpadded, err := padder.Unpad(data, int(blockSize))
+			if err != nil {
+				continue
+			}
+			if !bytes.HasPrefix(data, unpadded) || len(data)-len(unpadded) > int(blockSize) {
+				t.Fatalf("%s: Unpad(%x) = %x", name, data, unpadded)
+			}
+		}
+	})
+}
@@ -0,0 +1,226 @@
+// Package padding implements the padding schemes that extend a message to a whole number of cipher blocks.
+//
+// Unpad runs in constant time with respect to the content of the last block: it always inspects the whole
+// block and only reports whether the padding was valid at the end, so that the time it takes does not reveal
+// where the padding check failed. A padding check that leaks this is all a padding oracle attack needs.
+package padding
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidPadding is returned by Unpad when the padding of the message is malformed.
+var ErrInvalidPadding = errors.New("padding: invalid padding")
+
+// Padder pads messages to a multiple of a block size and removes that padding again.
+type Padder interface {
+	// Pad returns a copy of data extended to a multiple of blockSize bytes.
+	Pad(data []byte, blockSize int) ([]byte, error)
+	// Unpad removes the padding from data, whose length must be a non-zero multiple of blockSize.
+	// The returned slice aliases data.
+	Unpad(data []byte, blockSize int) ([]byte, error)
+}
+
+var (
+	// PKCS7 appends n bytes of value n (RFC 5652, section 6.3). A whole block is added to messages that are
+	// already a multiple of the block size.
+	PKCS7 Padder = pkcs7{}
+	// ANSIX923 appends n-1 zero bytes followed by one byte of value n (ANSI X9.23).
+	ANSIX923 Padder = ansiX923{}
+	// ISO7816 appends a 0x80 byte followed by zero bytes (ISO/IEC 7816-4, the "10*" padding of CMAC and OCB).
+	ISO7816 Padder = iso7816{}
+	// ISO10126 appends n-1 random bytes followed by one byte of value n (ISO 10126, withdrawn).
+	ISO10126 Padder = iso10126{}
+	// Zero appends zero bytes, and nothing if the message is already a multiple of the block size.
+	// Unpad removes every trailing zero byte of the last block, so messages that end with a zero byte
+	// do not survive a round trip.
+	Zero Padder = zero{}
+)
+
+// checkBlockSize validates the block size of the schemes that store the padding length in one byte.
+func checkBlockSize(blockSize int) error {
+	if blockSize < 1 || blockSize > 255 {
+		return fmt.Errorf("padding: block size must be between 1 and 255 bytes, got %d", blockSize)
+	}
+	return nil
+}
+
+// checkPadded validates the length of a padded message. The length is public, so it needs no constant-time check.
+func checkPadded(data []byte, blockSize int) error {
+	if err := checkBlockSize(blockSize); err != nil {
+		return err
+	}
+	if len(data) == 0 || len(data)%blockSize != 0 {
+		return ErrInvalidPadding
+	}
+	return nil
+}
+
+// pad returns a copy of data with room for n bytes of padding, and the padding slice to fill in.
+func pad(data []byte, n int) (padded, padding []byte) {
+	padded = make([]byte, len(data)+n)
+	copy(padded, data)
+	return padded, padded[len(data):]
+}
+
+// lengthByte reads the padding length n from the last byte of a block and returns it together with 1 if
+// 1 <= n <= blockSize, 0 otherwise.
+func lengthByte(data []byte, blockSize int) (n, ok int) {
+	n = int(data[len(data)-1])
+	ok = subtle.ConstantTimeLessOrEq(1, n) & subtle.ConstantTimeLessOrEq(n, blockSize)
+	return n, ok
+}
+
+// unpad returns data without its last n bytes if ok is 1, the result of the constant-time checks.
+func unpad(data []byte, n, ok int) ([]byte, error) {
+	if ok != 1 {
+		return nil, ErrInvalidPadding
+	}
+	return data[:len(data)-n], nil
+}
+
+type pkcs7 struct{}
+
+func (pkcs7) Pad(data []byte, blockSize int) ([]byte, error) {
+	if err := checkBlockSize(blockSize); err != nil {
+		return nil, err
+	}
+
+	n := blockSize - len(data)%blockSize
+	padded, padding := pad(data, n)
+	for i := range padding {
+		padding[i] = byte(n)
+	}
+	return padded, nil
+}
+
+func (pkcs7) Unpad(data []byte, blockSize int) ([]byte, error) {
+	if err := checkPadded(data, blockSize); err != nil {
+		return nil, err
+	}
+
+	n, ok := lengthByte(data, blockSize)
+	for i := 1; i <= blockSize; i++ {
+		// Every byte within the padding must equal n
+		inPadding := subtle.ConstantTimeLessOrEq(i, n)
+		ok &= subtle.ConstantTimeSelect(inPadding, subtle.ConstantTimeByteEq(data[len(data)-i], byte(n)), 1)
+	}
+	return unpad(data, n, ok)
+}
+
+type ansiX923 struct{}
+
+func (ansiX923) Pad(data []byte, blockSize int) ([]byte, error) {
+	if err := checkBlockSize(blockSize); err != nil {
+		return nil, err
+	}
+
+	n := blockSize - len(data)%blockSize
+	padded, padding := pad(data, n)
+	padding[n-1] = byte(n)
+	return padded, nil
+}
+
+func (ansiX923) Unpad(data []byte, blockSize int) ([]byte, error) {
+	if err := checkPadded(data, blockSize); err != nil {
+		return nil, err
+	}
+
+	n, ok := lengthByte(data, blockSize)
+	for i := 2; i <= blockSize; i++ {
+		// Every byte within the padding but the length byte must be zero
+		inPadding := subtle.ConstantTimeLessOrEq(i, n)
+		ok &= subtle.ConstantTimeSelect(inPadding, subtle.ConstantTimeByteEq(data[len(data)-i], 0), 1)
+	}
+	return unpad(data, n, ok)
+}
+
+type iso7816 struct{}
+
+func (iso7816) Pad(data []byte, blockSize int) ([]byte, error) {
+	if err := checkBlockSize(blockSize); err != nil {
+		return nil, err
+	}
+
+	n := blockSize - len(data)%blockSize
+	padded, padding := pad(data, n)
+	padding[0] = 0x80
+	return padded, nil
+}
+
+func (iso7816) Unpad(data []byte, blockSize int) ([]byte, error) {
+	if err := checkPadded(data, blockSize); err != nil {
+		return nil, err
+	}
+
+	// Scan the last block backwards: zero bytes until the first 0x80 marker, which ends the padding
+	n, found, ok := 0, 0, 1
+	for i := 1; i <= blockSize; i++ {
+		b := data[len(data)-i]
+		notFound := found ^ 1
+		isMarker := subtle.ConstantTimeByteEq(b, 0x80)
+
+		ok &= subtle.ConstantTimeSelect(notFound, subtle.ConstantTimeByteEq(b, 0)|isMarker, 1)
+		n = subtle.ConstantTimeSelect(notFound&isMarker, i, n)
+		found |= isMarker
+	}
+	return unpad(data, n, ok&found)
+}
+
+type iso10126 struct{}
+
+func (iso10126) Pad(data []byte, blockSize int) ([]byte, error) {
+	if err := checkBlockSize(blockSize); err != nil {
+		return nil, err
+	}
+
+	n := blockSize - len(data)%blockSize
+	padded, padding := pad(data, n)
+	if _, err := rand.Read(padding[:n-1]); err != nil {
+		return nil, err
+	}
+	padding[n-1] = byte(n)
+	return padded, nil
+}
+
+func (iso10126) Unpad(data []byte, blockSize int) ([]byte, error) {
+	if err := checkPadded(data, blockSize); err != nil {
+		return nil, err
+	}
+
+	// The padding bytes are random, only the length byte can be checked
+	n, ok := lengthByte(data, blockSize)
+	return unpad(data, n, ok)
+}
+
+type zero struct{}
+
+func (zero) Pad(data []byte, blockSize int) ([]byte, error) {
+	if blockSize < 1 {
+		return nil, fmt.Errorf("padding: block size must be positive, got %d", blockSize)
+	}
+
+	n := (blockSize - len(data)%blockSize) % blockSize
+	padded, _ := pad(data, n)
+	return padded, nil
+}
+
+func (zero) Unpad(data []byte, blockSize int) ([]byte, error) {
+	if blockSize < 1 {
+		return nil, fmt.Errorf("padding: block size must be positive, got %d", blockSize)
+	}
+	if len(data)%blockSize != 0 {
+		return nil, ErrInvalidPadding
+	}
+
+	// Count the trailing zero bytes of the last block without stopping at the first non-zero byte
+	n, trailing := 0, 1
+	for i := 1; i <= blockSize && i <= len(data); i++ {
+		trailing &= subtle.ConstantTimeByteEq(data[len(data)-i], 0)
+		n += trailing
+	}
+	return data[:len(data)-n], nil
+}
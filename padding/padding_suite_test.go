@@ -0,0 +1,13 @@
+package padding_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestPadding(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Padding Suit")
+}
@@ -0,0 +1,121 @@
+package padding_test
+
+import (
+	"github.com/japananh/crypto/padding"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("padding", func() {
+	message := []byte("Hello world!")
+
+	padTestVectors := []struct {
+		name   string
+		padder padding.Padder
+		want   []byte
+	}{
+		{name: "PKCS#7", padder: padding.PKCS7, want: append([]byte("Hello world!"), 4, 4, 4, 4)},
+		{name: "ANSI X9.23", padder: padding.ANSIX923, want: append([]byte("Hello world!"), 0, 0, 0, 4)},
+		{name: "ISO/IEC 7816-4", padder: padding.ISO7816, want: append([]byte("Hello world!"), 0x80, 0, 0, 0)},
+		{name: "zero", padder: padding.Zero, want: append([]byte("Hello world!"), 0, 0, 0, 0)},
+	}
+
+	for _, tv := range padTestVectors {
+		tv := tv
+
+		It("should pad and unpad with "+tv.name, func() {
+			padded, err := tv.padder.Pad(message, 16)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(padded).To(Equal(tv.want))
+
+			unpadded, err := tv.padder.Unpad(padded, 16)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(unpadded).To(Equal(message))
+		})
+	}
+
+	It("should end ISO 10126 padding with the padding length", func() {
+		padded, err := padding.ISO10126.Pad(message, 16)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(padded).To(HaveLen(16))
+		Expect(padded[:12]).To(Equal(message))
+		Expect(padded[15]).To(Equal(byte(4)))
+
+		unpadded, err := padding.ISO10126.Unpad(padded, 16)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(unpadded).To(Equal(message))
+	})
+
+	It("should add a whole block to messages that are a multiple of the block size", func() {
+		block := []byte("YELLOW SUBMARINE")
+		for _, padder := range []padding.Padder{padding.PKCS7, padding.ANSIX923, padding.ISO7816, padding.ISO10126} {
+			padded, err := padder.Pad(block, 16)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(padded).To(HaveLen(32))
+		}
+
+		padded, err := padding.Zero.Pad(block, 16)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(padded).To(Equal(block))
+	})
+
+	It("should support any block size up to 255 bytes", func() {
+		for _, blockSize := range []int{1, 8, 20, 255} {
+			padded, err := padding.PKCS7.Pad(message, blockSize)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(padded) % blockSize).To(BeZero())
+
+			unpadded, err := padding.PKCS7.Unpad(padded, blockSize)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(unpadded).To(Equal(message))
+		}
+
+		_, err := padding.PKCS7.Pad(message, 256)
+		Expect(err).To(HaveOccurred())
+		_, err = padding.PKCS7.Pad(message, 0)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should not modify the input", func() {
+		input := make([]byte, 12, 32)
+		copy(input, message)
+
+		_, err := padding.PKCS7.Pad(input, 16)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(input[:16]).To(Equal(append([]byte("Hello world!"), 0, 0, 0, 0)))
+	})
+
+	invalidTestVectors := []struct {
+		name   string
+		padder padding.Padder
+		input  []byte
+	}{
+		{name: "PKCS#7 with a zero length byte", padder: padding.PKCS7, input: append([]byte("Hello world!123"), 0)},
+		{name: "PKCS#7 with a length byte larger than the block", padder: padding.PKCS7, input: append([]byte("Hello world!123"), 17)},
+		{name: "PKCS#7 with inconsistent padding bytes", padder: padding.PKCS7, input: append([]byte("Hello world!"), 4, 3, 4, 4)},
+		{name: "PKCS#7 with a wrong first padding byte", padder: padding.PKCS7, input: append([]byte("Hello world!"), 5, 4, 4, 4)},
+		{name: "PKCS#7 with a partial block", padder: padding.PKCS7, input: []byte("Hello world!\x01")},
+		{name: "PKCS#7 with an empty input", padder: padding.PKCS7, input: []byte{}},
+		{name: "ANSI X9.23 with non-zero padding bytes", padder: padding.ANSIX923, input: append([]byte("Hello world!"), 0, 1, 0, 4)},
+		{name: "ANSI X9.23 with a zero length byte", padder: padding.ANSIX923, input: append([]byte("Hello world!123"), 0)},
+		{name: "ISO/IEC 7816-4 without a marker", padder: padding.ISO7816, input: make([]byte, 16)},
+		{name: "ISO/IEC 7816-4 with non-zero bytes after the marker", padder: padding.ISO7816, input: append([]byte("Hello world!"), 0x80, 0, 1, 0)},
+		{name: "ISO 10126 with a length byte larger than the block", padder: padding.ISO10126, input: append([]byte("Hello world!123"), 20)},
+		{name: "zero with a partial block", padder: padding.Zero, input: []byte("Hello world!")},
+	}
+
+	for _, tv := range invalidTestVectors {
+		tv := tv
+
+		It("should reject "+tv.name, func() {
+			_, err := tv.padder.Unpad(tv.input, 16)
+			Expect(err).To(HaveOccurred())
+		})
+	}
+
+	It("should report malformed padding as ErrInvalidPadding", func() {
+		_, err := padding.PKCS7.Unpad(append([]byte("Hello world!"), 4, 3, 4, 4), 16)
+		Expect(err).To(MatchError(padding.ErrInvalidPadding))
+	})
+})
@@ -0,0 +1,117 @@
+package crypto
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"fmt"
+	"hash"
+	"math/bits"
+)
+
+// pmac implements PMAC1, Rogaway's parallelizable block-cipher MAC.
+//
+// Unlike CMAC, every block except the last one is encrypted independently under its own offset,
+// so the blocks can be processed in any order and in parallel.
+type pmac struct {
+	block cipher.Block
+
+	// l[i] = L·x^i, used as the offset increment for block numbers with i trailing zeros.
+	// lInv = L·x^-1 masks a complete final block.
+	l    [64][macBlockSize]byte
+	lInv [macBlockSize]byte
+
+	offset [macBlockSize]byte
+	sigma  [macBlockSize]byte
+	count  uint64 // number of blocks already folded into sigma
+
+	// The last block of the message is kept in buf until Sum is called.
+	buf [macBlockSize]byte
+	n   int
+}
+
+// NewPMAC returns a hash.Hash computing PMAC1 with the given block cipher.
+// The block cipher must have a 16-byte block size.
+func NewPMAC(b cipher.Block) (hash.Hash, error) {
+	if b.BlockSize() != macBlockSize {
+		return nil, fmt.Errorf("pmac: block size must be %d bytes, got %d", macBlockSize, b.BlockSize())
+	}
+
+	p := &pmac{block: b}
+
+	// Subkey generation: L = E(0^128), then successive doublings and one halving of L
+	var l [macBlockSize]byte
+	b.Encrypt(l[:], l[:])
+	p.l[0] = l
+	for i := 1; i < len(p.l); i++ {
+		p.l[i] = gfDouble(p.l[i-1])
+	}
+	p.lInv = gfHalve(l)
+
+	return p, nil
+}
+
+func (p *pmac) Write(data []byte) (int, error) {
+	written := len(data)
+
+	for len(data) > 0 {
+		if p.n == macBlockSize {
+			p.processBlock()
+		}
+
+		copied := copy(p.buf[p.n:], data)
+		p.n += copied
+		data = data[copied:]
+	}
+
+	return written, nil
+}
+
+// processBlock folds the buffered block into sigma: Offset = Offset ^ L(ntz(i)), Σ = Σ ^ E(M[i] ^ Offset).
+func (p *pmac) processBlock() {
+	p.count++
+	subtle.XORBytes(p.offset[:], p.offset[:], p.l[bits.TrailingZeros64(p.count)][:])
+
+	var tmp [macBlockSize]byte
+	subtle.XORBytes(tmp[:], p.buf[:], p.offset[:])
+	p.block.Encrypt(tmp[:], tmp[:])
+	subtle.XORBytes(p.sigma[:], p.sigma[:], tmp[:])
+
+	p.n = 0
+}
+
+func (p *pmac) Sum(b []byte) []byte {
+	sigma := p.sigma
+
+	// The last block is not encrypted on its own: a complete block is masked with L·x^-1,
+	// an incomplete one is padded with 10* instead
+	if p.n == macBlockSize {
+		subtle.XORBytes(sigma[:], sigma[:], p.buf[:])
+		subtle.XORBytes(sigma[:], sigma[:], p.lInv[:])
+	} else {
+		var last [macBlockSize]byte
+		copy(last[:], p.buf[:p.n])
+		last[p.n] = 0x80
+		subtle.XORBytes(sigma[:], sigma[:], last[:])
+	}
+
+	var tag [macBlockSize]byte
+	p.block.Encrypt(tag[:], sigma[:])
+
+	return append(b, tag[:]...)
+}
+
+func (p *pmac) Reset() {
+	p.offset = [macBlockSize]byte{}
+	p.sigma = [macBlockSize]byte{}
+	p.count = 0
+	p.buf = [macBlockSize]byte{}
+	p.n = 0
+}
+
+func (p *pmac) Size() int {
+	return macBlockSize
+}
+
+func (p *pmac) BlockSize() int {
+	return macBlockSize
+}
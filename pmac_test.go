@@ -0,0 +1,103 @@
+package crypto_test
+
+import (
+	"crypto/aes"
+	"crypto/des"
+	"encoding/hex"
+
+	"github.com/japananh/crypto"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("crypto - pmac", func() {
+	key, err := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	if err != nil {
+		panic(err)
+	}
+
+	// sequentialBytes returns 00 01 02 ... of the given length, the message format used by the PMAC1 test vectors.
+	sequentialBytes := func(length int) []byte {
+		b := make([]byte, length)
+		for i := range b {
+			b[i] = byte(i)
+		}
+		return b
+	}
+
+	// Test vectors for PMAC1 with AES-128 published alongside the PMAC specification
+	pmacTestVectors := []struct {
+		name    string
+		message []byte
+		tag     string
+	}{
+		{name: "empty message", message: []byte{}, tag: "4399572cd6ea5341b8d35876a7098af7"},
+		{name: "3-byte message", message: sequentialBytes(3), tag: "256ba5193c1b991b4df0c51f388a9e27"},
+		{name: "16-byte message", message: sequentialBytes(16), tag: "ebbd822fa458daf6dfdad7c27da76338"},
+		{name: "20-byte message", message: sequentialBytes(20), tag: "0412ca150bbf79058d8c75a58c993f55"},
+		{name: "32-byte message", message: sequentialBytes(32), tag: "e97ac04e9e5e3399ce5355cd7407bc75"},
+		{name: "34-byte message", message: sequentialBytes(34), tag: "5cba7d5eb24f7c86ccc54604e53d5512"},
+		{name: "1000 zero bytes", message: make([]byte, 1000), tag: "c2c9fa1d9985f6f0d2aff915a0e8d910"},
+	}
+
+	for _, tt := range pmacTestVectors {
+		tt := tt
+		It("should match the PMAC1 test vector with "+tt.name, func() {
+			block, err := aes.NewCipher(key)
+			Expect(err).NotTo(HaveOccurred())
+
+			mac, err := crypto.NewPMAC(block)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = mac.Write(tt.message)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hex.EncodeToString(mac.Sum(nil))).To(Equal(tt.tag))
+		})
+	}
+
+	It("should give the same tag when the message is written in pieces", func() {
+		block, err := aes.NewCipher(key)
+		Expect(err).NotTo(HaveOccurred())
+
+		mac, err := crypto.NewPMAC(block)
+		Expect(err).NotTo(HaveOccurred())
+
+		message := make([]byte, 1000)
+		for start := 0; start < len(message); start += 7 {
+			end := start + 7
+			if end > len(message) {
+				end = len(message)
+			}
+			_, err := mac.Write(message[start:end])
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		Expect(hex.EncodeToString(mac.Sum(nil))).To(Equal("c2c9fa1d9985f6f0d2aff915a0e8d910"))
+	})
+
+	It("should not change its state on Sum and start over on Reset", func() {
+		block, err := aes.NewCipher(key)
+		Expect(err).NotTo(HaveOccurred())
+
+		mac, err := crypto.NewPMAC(block)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, _ = mac.Write(sequentialBytes(16))
+		Expect(hex.EncodeToString(mac.Sum(nil))).To(Equal("ebbd822fa458daf6dfdad7c27da76338"))
+
+		_, _ = mac.Write(sequentialBytes(20)[16:])
+		Expect(hex.EncodeToString(mac.Sum(nil))).To(Equal("0412ca150bbf79058d8c75a58c993f55"))
+
+		mac.Reset()
+		Expect(hex.EncodeToString(mac.Sum(nil))).To(Equal("4399572cd6ea5341b8d35876a7098af7"))
+	})
+
+	It("should reject a block cipher that does not have a 16-byte block", func() {
+		block, err := des.NewCipher(make([]byte, 8))
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = crypto.NewPMAC(block)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,79 @@
+package visualize
+
+import (
+	"fmt"
+	"io"
+)
+
+// labelWidth is the width of the left column, holding the arrows, boxes and labels.
+const labelWidth = 24
+
+// diagram writes a block as a vertical flow of values joined by pipes:
+//
+//	 P1                   6bc1bee22e409f96e93d7e117393172a
+//	  |
+//	 (+)<-- IV            000102030405060708090a0b0c0d0e0f
+//	  |
+//	+---+  in             6bc0bce12a459991e134741a7f9e1925
+//	| E |
+//	+---+  out            7649abac8119b246cee98e9b12e9197d
+//	  |
+//	 C1                   7649abac8119b246cee98e9b12e9197d
+//
+// The first write error is kept and reported by Trace.
+type diagram struct {
+	w   io.Writer
+	err error
+
+	// inFlow is set once the current block has a value to connect the next one to
+	inFlow bool
+}
+
+func (d *diagram) printf(format string, args ...interface{}) {
+	if d.err == nil {
+		_, d.err = fmt.Fprintf(d.w, format, args...)
+	}
+}
+
+// row prints a value after its label, padded to the left column.
+func (d *diagram) row(label string, value []byte) {
+	d.printf("%-*s%x\n", labelWidth, label, value)
+}
+
+// section starts a new part of the diagram.
+func (d *diagram) section(title string) {
+	d.printf("\n%s\n", title)
+	d.inFlow = false
+}
+
+// block starts the diagram of block i.
+func (d *diagram) block(i int) {
+	d.section(fmt.Sprintf("block %d", i))
+}
+
+func (d *diagram) connect() {
+	if d.inFlow {
+		d.printf("    |\n")
+	}
+	d.inFlow = true
+}
+
+// value prints a value in the flow.
+func (d *diagram) value(label string, value []byte) {
+	d.connect()
+	d.row("   "+label, value)
+}
+
+// xor prints the value XORed into the flow.
+func (d *diagram) xor(label string, value []byte) {
+	d.connect()
+	d.row("   (+)<-- "+label, value)
+}
+
+// cipher prints a block cipher call.
+func (d *diagram) cipher(c call) {
+	d.connect()
+	d.row("  +---+  in", c.in)
+	d.printf("  | E |\n")
+	d.row("  +---+  out", c.out)
+}
@@ -0,0 +1,286 @@
+// Package visualize renders how a block cipher mode of operation processes a message, block by block,
+// as a plain-text diagram: the IV or counter, the input and output of every block cipher call and the XORs
+// that chain the blocks together.
+//
+// The traces are recorded from the real implementations: the modes package for CBC, CFB, OFB and CTR,
+// and the GCM of this repository, all running over its readable AES.
+package visualize
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/japananh/crypto"
+	"github.com/japananh/crypto/modes"
+	"github.com/japananh/crypto/padding"
+)
+
+// Mode names a mode of operation that can be traced.
+type Mode string
+
+const (
+	ECB Mode = "ECB"
+	CBC Mode = "CBC"
+	CFB Mode = "CFB"
+	OFB Mode = "OFB"
+	CTR Mode = "CTR"
+	GCM Mode = "GCM"
+)
+
+// Modes lists the modes Trace supports.
+var Modes = []Mode{ECB, CBC, CFB, OFB, CTR, GCM}
+
+// ParseMode returns the Mode with the given name, ignoring case.
+func ParseMode(name string) (Mode, error) {
+	for _, m := range Modes {
+		if strings.EqualFold(name, string(m)) {
+			return m, nil
+		}
+	}
+	return "", fmt.Errorf("visualize: unknown mode %q", name)
+}
+
+// IVSize returns the size of the IV (the nonce for GCM) the mode needs, 0 for ECB.
+func (m Mode) IVSize() int {
+	switch m {
+	case ECB:
+		return 0
+	case GCM:
+		return crypto.GCMStandardNonceSize
+	default:
+		return crypto.AESBlockSize
+	}
+}
+
+// Trace encrypts plaintext with AES in the given mode and writes a per-block diagram of the computation to w.
+// key selects AES-128, AES-192 or AES-256. A nil iv is replaced by a random one; ECB takes no IV.
+func Trace(w io.Writer, mode Mode, key, iv, plaintext []byte) error {
+	rec := &recorder{}
+	block, err := crypto.NewAESCipher(key)
+	if err != nil {
+		return err
+	}
+	rec.Block = block
+
+	if iv == nil && mode.IVSize() > 0 {
+		if iv, err = modes.GenerateIV(mode.IVSize()); err != nil {
+			return err
+		}
+	}
+	if len(iv) != mode.IVSize() {
+		return fmt.Errorf("visualize: %s needs a %d-byte IV, got %d", mode, mode.IVSize(), len(iv))
+	}
+
+	d := &diagram{w: w}
+	d.printf("%s encryption with AES-%d\n", mode, 8*len(key))
+	d.row("key", key)
+	if len(iv) > 0 {
+		if mode == GCM {
+			d.row("nonce", iv)
+		} else {
+			d.row("IV", iv)
+		}
+	}
+
+	switch mode {
+	case ECB:
+		err = traceECB(d, rec, plaintext)
+	case CBC:
+		err = traceCBC(d, rec, iv, plaintext)
+	case CFB, OFB, CTR:
+		err = traceStream(d, rec, mode, iv, plaintext)
+	case GCM:
+		err = traceGCM(d, block, iv, plaintext)
+	default:
+		err = fmt.Errorf("visualize: unknown mode %q", mode)
+	}
+	if err != nil {
+		return err
+	}
+
+	return d.err
+}
+
+// traceECB encrypts every padded block on its own. ECB has no implementation in the modes package,
+// precisely because identical plaintext blocks give identical ciphertext blocks.
+func traceECB(d *diagram, rec *recorder, plaintext []byte) error {
+	padded, err := padding.PKCS7.Pad(plaintext, crypto.AESBlockSize)
+	if err != nil {
+		return err
+	}
+
+	for i, p := range blocks(padded) {
+		c := make([]byte, crypto.AESBlockSize)
+		rec.Encrypt(c, p)
+
+		d.block(i + 1)
+		d.value(fmt.Sprintf("P%d", i+1), p)
+		d.cipher(rec.calls[i])
+		d.value(fmt.Sprintf("C%d", i+1), c)
+	}
+
+	return nil
+}
+
+// traceCBC shows C_i = E(K, P_i xor C_(i-1)), with C_0 = IV.
+func traceCBC(d *diagram, rec *recorder, iv, plaintext []byte) error {
+	ciphertext, err := modes.NewCBC(rec).EncryptWithIV(iv, plaintext)
+	if err != nil {
+		return err
+	}
+	padded, err := padding.PKCS7.Pad(plaintext, crypto.AESBlockSize)
+	if err != nil {
+		return err
+	}
+
+	prevLabel, prev := "IV", iv
+	for i, c := range blocks(ciphertext[len(iv):]) {
+		d.block(i + 1)
+		d.value(fmt.Sprintf("P%d", i+1), padded[i*crypto.AESBlockSize:(i+1)*crypto.AESBlockSize])
+		d.xor(prevLabel, prev)
+		d.cipher(rec.calls[i])
+		d.value(fmt.Sprintf("C%d", i+1), c)
+
+		prevLabel, prev = fmt.Sprintf("C%d", i+1), c
+	}
+
+	return nil
+}
+
+// traceStream shows the three modes that turn AES into a stream cipher. They only differ in the input of the
+// block cipher: the previous ciphertext block for CFB, the previous output for OFB and a counter for CTR.
+func traceStream(d *diagram, rec *recorder, mode Mode, iv, plaintext []byte) error {
+	var m modes.Mode
+	switch mode {
+	case CFB:
+		m = modes.NewCFB(rec)
+	case OFB:
+		m = modes.NewOFB(rec)
+	default:
+		m = modes.NewCTR(rec)
+	}
+
+	ciphertext, err := m.EncryptWithIV(iv, plaintext)
+	if err != nil {
+		return err
+	}
+	ciphertext = ciphertext[len(iv):]
+
+	// The block cipher may run ahead of the message to buffer keystream, so only the first calls are shown
+	for i, p := range blocks(plaintext) {
+		call := rec.calls[i]
+
+		d.block(i + 1)
+		switch {
+		case mode == CTR:
+			d.value(fmt.Sprintf("CB%d", i+1), call.in)
+		case i == 0:
+			d.value("IV", call.in)
+		case mode == CFB:
+			d.value(fmt.Sprintf("C%d", i), call.in)
+		default:
+			d.value(fmt.Sprintf("O%d", i), call.in)
+		}
+		d.cipher(call)
+		if mode == OFB {
+			d.value(fmt.Sprintf("O%d", i+1), call.out)
+		}
+		d.xor(fmt.Sprintf("P%d", i+1), p)
+		d.value(fmt.Sprintf("C%d", i+1), ciphertext[i*crypto.AESBlockSize:i*crypto.AESBlockSize+len(p)])
+	}
+
+	return nil
+}
+
+// traceGCM shows the CTR encryption of every block from inc32(J0), then the GHASH chain and the tag.
+func traceGCM(d *diagram, block cipher.Block, nonce, plaintext []byte) error {
+	var (
+		h, j0, tag []byte
+		counters   [][]byte
+		keystreams [][]byte
+		ghashSteps [][]byte
+	)
+	tracer := func(kind crypto.GCMTraceKind, index int, b [crypto.AESBlockSize]byte) {
+		value := append([]byte(nil), b[:]...)
+		switch kind {
+		case crypto.GCMTraceHashKey:
+			h = value
+		case crypto.GCMTracePreCounter:
+			j0 = value
+		case crypto.GCMTraceCounter:
+			counters = append(counters, value)
+		case crypto.GCMTraceKeystream:
+			keystreams = append(keystreams, value)
+		case crypto.GCMTraceGHASH:
+			ghashSteps = append(ghashSteps, value)
+		case crypto.GCMTraceTag:
+			tag = value
+		}
+	}
+
+	aead, err := crypto.NewGCMWithTracer(block, len(nonce), tracer)
+	if err != nil {
+		return err
+	}
+	sealed := aead.Seal(nil, nonce, plaintext, nil)
+	ciphertext := sealed[:len(plaintext)]
+
+	d.row("H = E(K, 0)", h)
+	d.row("J0", j0)
+
+	for i, p := range blocks(plaintext) {
+		d.block(i + 1)
+		d.value(fmt.Sprintf("CB%d", i+1), counters[i])
+		d.cipher(call{in: counters[i], out: keystreams[i]})
+		d.xor(fmt.Sprintf("P%d", i+1), p)
+		d.value(fmt.Sprintf("C%d", i+1), ciphertext[i*crypto.AESBlockSize:i*crypto.AESBlockSize+len(p)])
+	}
+
+	// The last GHASH step absorbs len(A) || len(C); its result S is masked with E(K, J0)
+	d.section("tag")
+	for i, x := range ghashSteps {
+		d.row(fmt.Sprintf("   X%d", i+1), x)
+	}
+	s := ghashSteps[len(ghashSteps)-1]
+	mask := make([]byte, crypto.AESBlockSize)
+	subtle.XORBytes(mask, tag, s)
+	d.value("S", s)
+	d.xor("E(K, J0)", mask)
+	d.value("T", tag)
+
+	return nil
+}
+
+// blocks splits data into blocks; the last one may be partial.
+func blocks(data []byte) [][]byte {
+	var bs [][]byte
+	for len(data) > 0 {
+		n := crypto.AESBlockSize
+		if len(data) < n {
+			n = len(data)
+		}
+		bs = append(bs, data[:n])
+		data = data[n:]
+	}
+	return bs
+}
+
+// call is one invocation of the block cipher.
+type call struct {
+	in, out []byte
+}
+
+// recorder is a cipher.Block that remembers every block it encrypts, in order.
+type recorder struct {
+	cipher.Block
+	calls []call
+}
+
+func (r *recorder) Encrypt(dst, src []byte) {
+	in := append([]byte(nil), src[:r.BlockSize()]...)
+	r.Block.Encrypt(dst, src)
+	r.calls = append(r.calls, call{in: in, out: append([]byte(nil), dst[:r.BlockSize()]...)})
+}
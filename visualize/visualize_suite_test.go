@@ -0,0 +1,13 @@
+package visualize_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestVisualize(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Visualize Suit")
+}
@@ -0,0 +1,132 @@
+package visualize_test
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/japananh/crypto/visualize"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// NIST SP 800-38A, appendix F: AES-128 with the first two example blocks
+var (
+	key       = mustHex("2b7e151628aed2a6abf7158809cf4f3c")
+	iv        = mustHex("000102030405060708090a0b0c0d0e0f")
+	plaintext = mustHex("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51")
+)
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// trace returns the diagram of plaintext encrypted with mode.
+func trace(mode visualize.Mode, iv, plaintext []byte) string {
+	var buf bytes.Buffer
+	Expect(visualize.Trace(&buf, mode, key, iv, plaintext)).To(Succeed())
+	return buf.String()
+}
+
+// row matches a line of the diagram with the given label and hex value.
+func row(label, value string) *regexp.Regexp {
+	return regexp.MustCompile(`(?m)^\s*` + regexp.QuoteMeta(label) + `\s+` + value + `$`)
+}
+
+var _ = Describe("visualize - trace", func() {
+	It("should trace the SP 800-38A examples", func() {
+		testCases := []struct {
+			mode visualize.Mode
+			c1   string
+			c2   string
+		}{
+			{visualize.ECB, "3ad77bb40d7a3660a89ecaf32466ef97", "f5d3d58503b9699de785895a96fdbaaf"},
+			{visualize.CBC, "7649abac8119b246cee98e9b12e9197d", "5086cb9b507219ee95db113a917678b2"},
+			{visualize.CFB, "3b3fd92eb72dad20333449f8e83cfb4a", "c8a64537a0b3a93fcde3cdad9f1ce58b"},
+			{visualize.OFB, "3b3fd92eb72dad20333449f8e83cfb4a", "7789508d16918f03f53c52dac54ed825"},
+		}
+
+		for _, tc := range testCases {
+			tc := tc
+			var modeIV []byte
+			if tc.mode != visualize.ECB {
+				modeIV = iv
+			}
+
+			out := trace(tc.mode, modeIV, plaintext)
+			Expect(out).To(MatchRegexp(row("C1", tc.c1).String()), string(tc.mode))
+			Expect(out).To(MatchRegexp(row("C2", tc.c2).String()), string(tc.mode))
+		}
+	})
+
+	It("should show the chaining of every mode", func() {
+		out := trace(visualize.CBC, iv, plaintext)
+		Expect(out).To(MatchRegexp(row("(+)<-- IV", hex.EncodeToString(iv)).String()))
+		Expect(out).To(MatchRegexp(row("(+)<-- C1", "7649abac8119b246cee98e9b12e9197d").String()))
+		Expect(out).To(MatchRegexp(row("+---+  in", "6bc0bce12a459991e134741a7f9e1925").String()))
+
+		out = trace(visualize.CFB, iv, plaintext)
+		Expect(out).To(MatchRegexp(row("+---+  in", "3b3fd92eb72dad20333449f8e83cfb4a").String()))
+
+		out = trace(visualize.OFB, iv, plaintext)
+		Expect(out).To(MatchRegexp(row("O1", "50fe67cc996d32b6da0937e99bafec60").String()))
+
+		counter := mustHex("f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+		out = trace(visualize.CTR, counter, plaintext)
+		Expect(out).To(MatchRegexp(row("CB2", "f0f1f2f3f4f5f6f7f8f9fafbfcfdff00").String()))
+		Expect(out).To(MatchRegexp(row("C1", "874d6191b620e3261bef6864990db6ce").String()))
+		Expect(out).To(MatchRegexp(row("C2", "9806f66b7970fdff8617187bb9fffdff").String()))
+	})
+
+	It("should trace a partial last block", func() {
+		out := trace(visualize.CTR, iv, plaintext[:20])
+		Expect(out).To(MatchRegexp(row("(+)<-- P2", "ae2d8a57").String()))
+		Expect(strings.Count(out, "block ")).To(Equal(2))
+	})
+
+	It("should trace the GCM tag", func() {
+		nonce := iv[:12]
+
+		block, err := aes.NewCipher(key)
+		Expect(err).NotTo(HaveOccurred())
+		aead, err := cipher.NewGCM(block)
+		Expect(err).NotTo(HaveOccurred())
+		sealed := aead.Seal(nil, nonce, plaintext, nil)
+
+		out := trace(visualize.GCM, nonce, plaintext)
+		Expect(out).To(MatchRegexp(row("J0", "000102030405060708090a0b00000001").String()))
+		Expect(out).To(MatchRegexp(row("C1", hex.EncodeToString(sealed[:16])).String()))
+		Expect(out).To(MatchRegexp(row("T", hex.EncodeToString(sealed[len(plaintext):])).String()))
+	})
+
+	It("should use a random IV when none is given", func() {
+		Expect(trace(visualize.CBC, nil, plaintext)).To(ContainSubstring("IV"))
+	})
+
+	It("should reject bad parameters", func() {
+		var buf bytes.Buffer
+		Expect(visualize.Trace(&buf, visualize.CBC, key, iv[:8], plaintext)).NotTo(Succeed())
+		Expect(visualize.Trace(&buf, visualize.CBC, key[:5], iv, plaintext)).NotTo(Succeed())
+		Expect(visualize.Trace(&buf, visualize.Mode("XTS"), key, nil, plaintext)).NotTo(Succeed())
+	})
+
+	It("should parse mode names", func() {
+		for _, m := range visualize.Modes {
+			parsed, err := visualize.ParseMode(strings.ToLower(string(m)))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(parsed).To(Equal(m))
+		}
+
+		_, err := visualize.ParseMode("XTS")
+		Expect(err).To(MatchError(fmt.Sprintf("visualize: unknown mode %q", "XTS")))
+	})
+})